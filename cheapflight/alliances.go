@@ -0,0 +1,55 @@
+package cheapflight
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+//go:embed data/alliances.json
+var alliancesJSON []byte
+
+// alliances maps each --alliance name to the IATA codes of its member airlines.
+var alliances = func() map[string]map[string]bool {
+	var members map[string][]string
+	if err := json.Unmarshal(alliancesJSON, &members); err != nil {
+		panic(err)
+	}
+	alliances := make(map[string]map[string]bool)
+	for alliance, codes := range members {
+		alliances[alliance] = make(map[string]bool)
+		for _, code := range codes {
+			alliances[alliance][code] = true
+		}
+	}
+	return alliances
+}()
+
+func allianceNames() []string {
+	var names []string
+	for name := range alliances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// carrierCode returns the airline code of a flight number such as "UA 123".
+func carrierCode(flightNumber string) string {
+	code, _, _ := strings.Cut(strings.TrimSpace(flightNumber), " ")
+	return strings.ToUpper(code)
+}
+
+// inAlliance reports whether every flight of o is flown by a member of alliance.
+func inAlliance(o flights.FullOffer, alliance string) bool {
+	members := alliances[alliance]
+	for _, f := range o.Flight {
+		if !members[carrierCode(f.FlightNumber)] {
+			return false
+		}
+	}
+	return len(o.Flight) > 0
+}
@@ -0,0 +1,39 @@
+package cheapflight
+
+import "time"
+
+// anonymousDateLayout coarsens dates to their month with --anonymize.
+const anonymousDateLayout = "2006-01"
+
+// anonymize returns copies of messages safe to share publicly: booking links
+// and flight details are removed and dates are coarsened to their month,
+// keeping prices and routes.
+func anonymize(messages []Message) []Message {
+	anonymized := make([]Message, len(messages))
+	for i, m := range messages {
+		anonymized[i] = anonymizeMessage(m)
+	}
+	return anonymized
+}
+
+func anonymizeMessage(m Message) Message {
+	m.Url = ""
+	m.Segments = nil
+	m.departure = monthOf(m.departure)
+	m.arrival = monthOf(m.arrival)
+	m.returnDate = monthOf(m.returnDate)
+	m.Start = m.departure.Format(anonymousDateLayout)
+	m.End = m.returnDate.Format(anonymousDateLayout)
+	if m.Legs != nil {
+		m.Legs = anonymize(m.Legs)
+	}
+	return m
+}
+
+// monthOf returns midnight UTC on the first day of t's month, or the zero time for a zero t.
+func monthOf(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,53 @@
+package cheapflight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	outbound := newMessage(offerOn(day2, 120), "https://example.com/out")
+	inbound := newMessage(offerOn(day4, 130), "https://example.com/in")
+	split := newMessage(offerOn(day2, 250), "https://example.com/trip")
+	split.Legs = []Message{outbound, inbound}
+
+	tests := []struct {
+		name string
+		m    Message
+	}{
+		{name: "round trip", m: newMessage(offerOn(day2, 250), "https://example.com/trip")},
+		{name: "split legs", m: split},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := anonymize([]Message{tt.m})[0]
+
+			for _, m := range append([]Message{got}, got.Legs...) {
+				if m.Url != "" || m.Segments != nil {
+					t.Errorf("kept url %q and segments %v", m.Url, m.Segments)
+				}
+				if m.Start != "2030-03" || m.End != "2030-03" {
+					t.Errorf("dates %s to %s, want only the month 2030-03", m.Start, m.End)
+				}
+				if m.departure.Day() != 1 || m.returnDate.Day() != 1 {
+					t.Errorf("departure %v and return %v not coarsened to the month", m.departure, m.returnDate)
+				}
+			}
+			if got.Price != tt.m.Price || got.Src != tt.m.Src || got.Dst != tt.m.Dst {
+				t.Errorf("got %d %s-%s, want price and route kept", got.Price, got.Src, got.Dst)
+			}
+			if tt.m.Url == "" || (len(tt.m.Legs) > 0 && tt.m.Legs[0].Url == "") {
+				t.Error("anonymize modified the original message")
+			}
+
+			var buf bytes.Buffer
+			if err := (jsonRenderer{}).Render(&buf, []Message{got}); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "example.com") || strings.Contains(buf.String(), "2030-03-0") {
+				t.Errorf("output leaks links or dates:\n%s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package cheapflight
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	backoffBase = time.Second
+	backoffMax  = time.Minute
+)
+
+// backoff computes exponential retry delays with full jitter. Its random source
+// is seeded from --seed so that runs can be reproduced.
+type backoff struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newBackoff(seed int64) *backoff {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &backoff{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Delay returns a random duration between 0 and base*2^attempt, capped at backoffMax.
+func (b *backoff) Delay(attempt int) time.Duration {
+	ceiling := backoffMax
+	if attempt < 6 {
+		ceiling = backoffBase << attempt
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(b.rng.Int63n(int64(ceiling) + 1))
+}
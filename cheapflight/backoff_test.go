@@ -0,0 +1,49 @@
+package cheapflight
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func delays(b *backoff, n int) []time.Duration {
+	var d []time.Duration
+	for attempt := 0; attempt < n; attempt++ {
+		d = append(d, b.Delay(attempt))
+	}
+	return d
+}
+
+func TestBackoffSeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     int64
+		wantSame bool
+	}{
+		{name: "same seed", a: 42, b: 42, wantSame: true},
+		{name: "different seeds", a: 42, b: 43, wantSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := delays(newBackoff(tt.a), 10), delays(newBackoff(tt.b), 10)
+			if same := reflect.DeepEqual(a, b); same != tt.wantSame {
+				t.Errorf("seeds %d and %d gave %v and %v, want same %v", tt.a, tt.b, a, b, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestBackoffDelayBounds(t *testing.T) {
+	b := newBackoff(1)
+	for attempt := 0; attempt < 10; attempt++ {
+		ceiling := backoffMax
+		if attempt < 6 {
+			ceiling = backoffBase << attempt
+		}
+		for i := 0; i < 100; i++ {
+			if d := b.Delay(attempt); d < 0 || d > ceiling {
+				t.Fatalf("Delay(%d) = %s, want between 0 and %s", attempt, d, ceiling)
+			}
+		}
+	}
+}
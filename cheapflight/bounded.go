@@ -0,0 +1,49 @@
+package cheapflight
+
+import (
+	"container/heap"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// boundedOffers accumulates offers, keeping only the max best seen so far so
+// memory stays flat however many offers a search returns. The worst kept offer
+// sits at the root of the heap. A max of 0 or less keeps every offer.
+type boundedOffers struct {
+	max    int
+	less   offerLess
+	offers []flights.FullOffer
+}
+
+func newBoundedOffers(max int, less offerLess) *boundedOffers {
+	return &boundedOffers{max: max, less: less}
+}
+
+func (b *boundedOffers) Len() int           { return len(b.offers) }
+func (b *boundedOffers) Less(i, j int) bool { return b.less(b.offers[j], b.offers[i]) }
+func (b *boundedOffers) Swap(i, j int)      { b.offers[i], b.offers[j] = b.offers[j], b.offers[i] }
+func (b *boundedOffers) Push(x interface{}) { b.offers = append(b.offers, x.(flights.FullOffer)) }
+func (b *boundedOffers) Pop() interface{} {
+	last := b.offers[len(b.offers)-1]
+	b.offers = b.offers[:len(b.offers)-1]
+	return last
+}
+
+func (b *boundedOffers) Add(offers ...flights.FullOffer) {
+	if b.max <= 0 {
+		b.offers = append(b.offers, offers...)
+		return
+	}
+
+	for _, o := range offers {
+		if o.Price == 0 {
+			continue
+		}
+		if len(b.offers) < b.max {
+			heap.Push(b, o)
+		} else if b.less(o, b.offers[0]) {
+			b.offers[0] = o
+			heap.Fix(b, 0)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cheapflight
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestBoundedOffers(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var many []float64
+	for i := 0; i < 1000; i++ {
+		many = append(many, float64(100+rng.Intn(900)))
+	}
+	cheapest := func(p []float64, n int) []float64 {
+		sorted := append([]float64(nil), p...)
+		sort.Float64s(sorted)
+		return sorted[:n]
+	}
+
+	tests := []struct {
+		name   string
+		max    int
+		prices []float64
+		want   []float64
+	}{
+		{name: "more offers than the cap", max: 5, prices: many, want: cheapest(many, 5)},
+		{name: "fewer offers than the cap", max: 5, prices: []float64{300, 100}, want: []float64{100, 300}},
+		{name: "unpriced offers skipped", max: 2, prices: []float64{0, 300, 0, 200, 400}, want: []float64{200, 300}},
+		{name: "no cap keeps all", max: 0, prices: []float64{300, 100, 200}, want: []float64{100, 200, 300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBoundedOffers(tt.max, Config{}.lessOffer)
+			for _, p := range tt.prices {
+				b.Add(offerOn(day1, p))
+				if tt.max > 0 && b.Len() > tt.max {
+					t.Fatalf("holding %d offers, more than the cap of %d", b.Len(), tt.max)
+				}
+			}
+
+			got := prices(b.offers)
+			sort.Float64s(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxResultsBoundsSearch(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		var offers []flights.FullOffer
+		for p := 900.0; p >= 100; p -= 100 {
+			offers = append(offers, offerOn(args.Date, p+float64(args.Date.Day())))
+		}
+		return offers, nil, nil
+	}}
+	cfg := withStub(Config{MaxResults: 3}, api)
+
+	var trips []datedTrip
+	for _, d := range []time.Time{day1, day2, day3, day4} {
+		trips = append(trips, datedTrip{departure: d, ret: d.AddDate(0, 0, 3)})
+	}
+	set, err := queryTrips(context.Background(), cfg.session(), flights.PriceGraphArgs{Options: roundTrip()}, trips, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := prices(set.offers)
+	sort.Float64s(got)
+	if want := []float64{101, 102, 103}; !reflect.DeepEqual(got, want) {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package cheapflight
+
+import (
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// errAPICallLimit is returned by calls made after --limit-api-calls was reached.
+var errAPICallLimit = errors.New("API call limit reached")
+
+// budget counts down a resource shared by the searches of a check: the
+// retries allowed by --retry-budget, so a flaky API cannot stretch one check
+// out over hours, or the flights API calls allowed by --limit-api-calls, to
+// protect quota. A nil budget never runs out.
+type budget struct {
+	n     int64
+	left  atomic.Int64
+	log   *slog.Logger
+	spent string
+}
+
+// newBudget returns a budget of n, or nil for no limit when n is 0. spent is
+// logged as a warning when the budget runs out.
+func newBudget(n int, log *slog.Logger, spent string) *budget {
+	if n == 0 {
+		return nil
+	}
+	b := &budget{n: int64(n), log: log, spent: spent}
+	b.left.Store(b.n)
+	return b
+}
+
+// reset refills the budget, which the watch loop does before every check.
+func (b *budget) reset() {
+	if b == nil {
+		return
+	}
+	b.left.Store(b.n)
+}
+
+// take uses up one unit, reporting false when none are left.
+func (b *budget) take() bool {
+	if b == nil {
+		return true
+	}
+	left := b.left.Add(-1)
+	if left == -1 {
+		b.log.Warn(b.spent)
+	}
+	return left >= 0
+}
@@ -0,0 +1,161 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestRetryBudget(t *testing.T) {
+	authErr := errors.New("unexpected status code: 401")
+	tests := []struct {
+		name       string
+		budget     int
+		searches   int
+		wantCalls  int
+		wantErrors int
+	}{
+		// Every session is rejected, so each search is retried once while the budget lasts.
+		{name: "budget spent", budget: 2, searches: 4, wantCalls: 6, wantErrors: 4},
+		{name: "budget left", budget: 3, searches: 1, wantCalls: 2, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+				return nil, authErr
+			}}
+			cfg := withStub(Config{retries: newBudget(tt.budget, discardLog, "retry budget spent")}, api)
+
+			errs := 0
+			for i := 0; i < tt.searches; i++ {
+				if _, err := cfg.session().GetPriceGraph(context.Background(), flights.PriceGraphArgs{}); err != nil {
+					errs++
+				}
+			}
+			if calls, _, _ := api.calls(); calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+			if errs != tt.wantErrors {
+				t.Errorf("%d searches failed, want %d", errs, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestRetryBudgetSharedAndReset(t *testing.T) {
+	api := &stubAPI{}
+	cfg := withStub(Config{RetryOnEmpty: true, MaxRetries: 3, retries: newBudget(1, discardLog, "retry budget spent")}, api)
+	cfg.backoff = newBackoff(1)
+
+	// The retries of empty responses draw on the same budget.
+	for i := 0; i < 2; i++ {
+		if _, _, err := getOffers(context.Background(), cfg.session(), flights.Args{Date: day1}, true, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, calls, _ := api.calls(); calls != 3 {
+		t.Errorf("made %d offers calls, want the one retry the budget allows", calls)
+	}
+
+	// The watch loop refills the budget before the next check.
+	cfg.retries.reset()
+	if _, _, err := getOffers(context.Background(), cfg.session(), flights.Args{Date: day1}, true, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, calls, _ := api.calls(); calls != 5 {
+		t.Errorf("made %d offers calls after the reset, want 5", calls)
+	}
+}
+
+func TestLimitAPICalls(t *testing.T) {
+	// A search of four dates makes a price graph call, an offers call per date
+	// and a booking link call for the cheapest offer: six calls in all.
+	tests := []struct {
+		limit       int
+		wantCalls   int
+		wantErr     bool
+		wantLink    bool
+		wantPartial bool
+	}{
+		{limit: 0, wantCalls: 6, wantLink: true},
+		{limit: 6, wantCalls: 6, wantLink: true},
+		{limit: 5, wantCalls: 5, wantPartial: true},
+		{limit: 3, wantCalls: 3, wantPartial: true},
+		{limit: 1, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.limit), func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{fmt.Sprintf("--limit-api-calls=%d", tt.limit)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &stubAPI{
+				priceGraph: pricedEveryDay,
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					return []flights.FullOffer{offerOn(args.Date, 250)}, nil, nil
+				},
+			}
+			cfg = withStub(cfg, api)
+
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+			messages, err := SearchOffers(args, "", cfg)
+
+			if priceGraph, offers, urls := api.calls(); priceGraph+offers+urls != tt.wantCalls {
+				t.Errorf("made %d price graph, %d offers and %d booking link calls, want %d in all", priceGraph, offers, urls, tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error without any offers", messages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 || messages[0].Price != 250 {
+				t.Fatalf("got %+v, want the offer found before the limit", messages)
+			}
+			m := messages[0]
+			if hasLink := m.Url != ""; hasLink != tt.wantLink {
+				t.Errorf("has a booking link %v, want %v", hasLink, tt.wantLink)
+			}
+			if m.Partial != tt.wantPartial {
+				t.Errorf("Partial %v, want %v", m.Partial, tt.wantPartial)
+			}
+			if noted := strings.Contains(FormatMessageBody(m), "API call limit reached"); noted != tt.wantPartial {
+				t.Errorf("output notes the limit %v, want %v:\n%s", noted, tt.wantPartial, FormatMessageBody(m))
+			}
+		})
+	}
+}
+
+func TestLimitAPICallsReset(t *testing.T) {
+	api := &stubAPI{}
+	cfg := withStub(Config{apiCalls: newBudget(2, discardLog, "API call limit reached")}, api)
+
+	call := func() error {
+		_, err := cfg.session().GetPriceGraph(context.Background(), flights.PriceGraphArgs{})
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := call(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := call(); !errors.Is(err, errAPICallLimit) {
+		t.Fatalf("third call returned %v, want errAPICallLimit", err)
+	}
+
+	// The watch loop refills the limit before the next check.
+	cfg.apiCalls.reset()
+	if err := call(); err != nil {
+		t.Errorf("call after the reset returned %v", err)
+	}
+	if calls, _, _ := api.calls(); calls != 3 {
+		t.Errorf("made %d calls, want 3", calls)
+	}
+}
@@ -0,0 +1,40 @@
+package cheapflight
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ephemeralParams are query parameters that only track a visit or session and
+// do not change the page a booking link opens.
+var ephemeralParams = map[string]bool{
+	"gclid":    true,
+	"fbclid":   true,
+	"msclkid":  true,
+	"ved":      true,
+	"ei":       true,
+	"sa":       true,
+	"usg":      true,
+	"authuser": true,
+	"sxsrf":    true,
+}
+
+// canonicalURL strips ephemeral and utm_ tracking parameters from a booking
+// link and orders the rest by name, so repeated runs give identical links for
+// the same search. Links that do not parse are returned unchanged.
+func canonicalURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return raw
+	}
+
+	query := u.Query()
+	for name := range query {
+		if ephemeralParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u.String()
+}
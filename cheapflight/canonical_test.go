@@ -0,0 +1,39 @@
+package cheapflight
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "tracking params",
+			in:   "https://www.google.com/travel/flights/search?tfs=CBwQAhoe&utm_source=newsletter&gclid=abc&hl=en&UTM_Campaign=x&ved=1",
+			want: "https://www.google.com/travel/flights/search?hl=en&tfs=CBwQAhoe",
+		},
+		{
+			name: "order and fragment",
+			in:   "https://www.google.com/travel/flights/search?tfs=CBwQAhoe&curr=USD#flt",
+			want: "https://www.google.com/travel/flights/search?curr=USD&tfs=CBwQAhoe",
+		},
+		{
+			name: "already canonical",
+			in:   "https://www.google.com/travel/flights/search?tfs=CBwQAhoe",
+			want: "https://www.google.com/travel/flights/search?tfs=CBwQAhoe",
+		},
+		{name: "empty", in: "", want: ""},
+		{name: "unparseable", in: "://bad", want: "://bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalURL(tt.in); got != tt.want {
+				t.Errorf("canonicalURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if got := canonicalURL(canonicalURL(tt.in)); got != tt.want {
+				t.Errorf("canonicalURL is not idempotent: %q", got)
+			}
+		})
+	}
+}
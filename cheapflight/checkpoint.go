@@ -0,0 +1,132 @@
+package cheapflight
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// checkpointMessage is a Message as saved in a checkpoint, keeping the times
+// the JSON form of a Message leaves out.
+type checkpointMessage struct {
+	Message
+	Departure  time.Time `json:"departure"`
+	Arrival    time.Time `json:"arrival"`
+	ReturnDate time.Time `json:"return_date"`
+}
+
+// checkpointFile is the saved form of a checkpoint.
+type checkpointFile struct {
+	Search string                         `json:"search"`
+	Routes map[string][]checkpointMessage `json:"routes"`
+}
+
+// checkpoint records the results of each route searched so far, so that a
+// batch interrupted part way through resumes with the remaining routes. A nil
+// checkpoint records nothing.
+type checkpoint struct {
+	path   string
+	search string
+	routes map[string][]checkpointMessage
+	// discarded is set when the file held routes of a different search.
+	discarded bool
+}
+
+// checkpointSearch identifies a search by its arguments and resolved date
+// range, which differ from run to run with a --preset.
+func checkpointSearch(args []string, searchArgs flights.PriceGraphArgs) string {
+	key := fmt.Sprintf("%q|%s|%s", args, dateKey(searchArgs.RangeStartDate), dateKey(searchArgs.RangeEndDate))
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCheckpoint reads the checkpoint of search from path. Routes saved by a
+// different search are discarded rather than resumed from.
+func loadCheckpoint(path, search string) (*checkpoint, error) {
+	c := &checkpoint{path: path, search: search, routes: make(map[string][]checkpointMessage)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved checkpointFile
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if saved.Search != search {
+		c.discarded = true
+		return c, nil
+	}
+	if saved.Routes != nil {
+		c.routes = saved.Routes
+	}
+	return c, nil
+}
+
+// Done returns the results of r if it was already searched.
+func (c *checkpoint) Done(r route) ([]Message, bool) {
+	if c == nil {
+		return nil, false
+	}
+	saved, ok := c.routes[r.String()]
+	if !ok {
+		return nil, false
+	}
+	messages := make([]Message, len(saved))
+	for i, s := range saved {
+		messages[i] = s.Message
+		messages[i].departure, messages[i].arrival, messages[i].returnDate = s.Departure, s.Arrival, s.ReturnDate
+	}
+	return messages, true
+}
+
+// Record saves the results of r.
+func (c *checkpoint) Record(r route, messages []Message) error {
+	if c == nil {
+		return nil
+	}
+	saved := make([]checkpointMessage, len(messages))
+	for i, m := range messages {
+		saved[i] = checkpointMessage{Message: m, Departure: m.departure, Arrival: m.arrival, ReturnDate: m.returnDate}
+	}
+	c.routes[r.String()] = saved
+
+	b, err := json.Marshal(checkpointFile{Search: c.search, Routes: c.routes})
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Restart forgets the routes searched so far without removing the file, which
+// is rewritten as soon as the next route is recorded.
+func (c *checkpoint) Restart() {
+	if c == nil {
+		return
+	}
+	c.routes = make(map[string][]checkpointMessage)
+}
+
+// Clear forgets every route once the whole batch has completed.
+func (c *checkpoint) Clear() error {
+	if c == nil {
+		return nil
+	}
+	c.routes = make(map[string][]checkpointMessage)
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cheapflight
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	routes := []route{{Src: "SFO", Dst: "JFK"}, {Src: "OAK", Dst: "BOS"}, {Src: "LAX", Dst: "ORD"}}
+	search := checkpointSearch([]string{"SFO", "JFK"}, routeArgs())
+
+	tests := []struct {
+		name         string
+		resumeSearch string
+		wantQueried  []string
+	}{
+		{name: "same search", resumeSearch: search, wantQueried: []string{"OAK"}},
+		{name: "different search", resumeSearch: checkpointSearch([]string{"SFO", "EWR"}, routeArgs()), wantQueried: []string{"SFO", "OAK", "LAX"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+			// The first run is interrupted by OAK failing.
+			resume, err := loadCheckpoint(path, search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := withStub(Config{}, routeStub("OAK"))
+			if _, err := searchRoutes(routes, routeArgs(), "", cfg, make(map[route][]float64), resume); err == nil {
+				t.Fatal("first run succeeded, want OAK to fail")
+			}
+
+			resume, err = loadCheckpoint(path, tt.resumeSearch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.resumeSearch != search; resume.discarded != want {
+				t.Errorf("discarded = %v, want %v", resume.discarded, want)
+			}
+			api := routeStub()
+			messages, err := searchRoutes(routes, routeArgs(), "", withStub(Config{}, api), make(map[route][]float64), resume)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var queried []string
+			for _, call := range api.priceGraphCalls {
+				queried = append(queried, call.SrcAirports[0])
+			}
+			if !reflect.DeepEqual(queried, tt.wantQueried) {
+				t.Errorf("resumed run searched %q, want %q", queried, tt.wantQueried)
+			}
+			if len(messages) != len(routes) {
+				t.Errorf("resumed run reported %d routes, want all %d", len(messages), len(routes))
+			}
+		})
+	}
+}
+
+func TestCheckpointSearch(t *testing.T) {
+	args := []string{"SFO", "JFK", "03-01-2030"}
+	later := routeArgs()
+	later.RangeEndDate = day4
+
+	tests := []struct {
+		name       string
+		args       []string
+		searchArgs flights.PriceGraphArgs
+		wantSame   bool
+	}{
+		{name: "same search", args: args, searchArgs: routeArgs(), wantSame: true},
+		{name: "different argument", args: []string{"SFO", "JFK", "03-02-2030"}, searchArgs: routeArgs()},
+		{name: "arguments split differently", args: []string{"SFO JFK", "03-01-2030"}, searchArgs: routeArgs()},
+		{name: "different date range", args: args, searchArgs: later},
+	}
+	want := checkpointSearch(args, routeArgs())
+	for _, tt := range tests {
+		if got := checkpointSearch(tt.args, tt.searchArgs); (got == want) != tt.wantSame {
+			t.Errorf("%s: same key = %v, want %v", tt.name, got == want, tt.wantSame)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cheapflight
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+	"github.com/krisukox/google-flights-api/iata"
+)
+
+//go:embed data/ambiguous_cities.json
+var ambiguousCitiesJSON []byte
+
+// ambiguousCities maps lower case city names shared by several served cities
+// to a description of each candidate and its airports.
+var ambiguousCities = func() map[string][]string {
+	cities := make(map[string][]string)
+	if err := json.Unmarshal(ambiguousCitiesJSON, &cities); err != nil {
+		panic(err)
+	}
+	return cities
+}()
+
+// warnAmbiguousCities logs a warning for every city that could refer to more
+// than one place, suggesting an IATA code instead.
+func warnAmbiguousCities(log *slog.Logger, cities []string) {
+	for _, city := range cities {
+		if matches, ok := ambiguousCities[strings.ToLower(city)]; ok {
+			log.Warn("ambiguous city name, pass an IATA airport code to pick one", "city", city, "matches", strings.Join(matches, "; "))
+		}
+	}
+}
+
+// withAirportCities returns a copy of args searching the city of every airport
+// instead of the airport itself, for --fallback-to-city. It reports false when
+// no airport could be mapped to a city.
+func withAirportCities(args flights.PriceGraphArgs) (flights.PriceGraphArgs, bool) {
+	var srcChanged, dstChanged bool
+	args.SrcAirports, args.SrcCities, srcChanged = airportsToCities(args.SrcAirports, args.SrcCities)
+	args.DstAirports, args.DstCities, dstChanged = airportsToCities(args.DstAirports, args.DstCities)
+	return args, srcChanged || dstChanged
+}
+
+// airportsToCities moves every airport with a known city into cities, keeping
+// the airports it cannot map.
+func airportsToCities(airports, cities []string) ([]string, []string, bool) {
+	var kept []string
+	mapped := append([]string(nil), cities...)
+	for _, airport := range airports {
+		city := iata.IATATimeZone(airport).City
+		if city == "" {
+			kept = append(kept, airport)
+			continue
+		}
+		if !containsFold(mapped, city) {
+			mapped = append(mapped, city)
+		}
+	}
+	return kept, mapped, len(kept) != len(airports)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// coveredAirports maps every airport in airports whose city is also among
+// cities to that city. A city search already covers the city's airports, so
+// searching such an airport as well only repeats queries.
+func coveredAirports(airports, cities []string) map[string]string {
+	covered := make(map[string]string)
+	for _, airport := range airports {
+		city := iata.IATATimeZone(airport).City
+		for _, c := range cities {
+			if city != "" && strings.EqualFold(c, city) {
+				covered[airport] = c
+			}
+		}
+	}
+	return covered
+}
+
+// withoutCoveredAirports drops the airports already searched through their city.
+func withoutCoveredAirports(airports, cities []string) []string {
+	covered := coveredAirports(airports, cities)
+	var kept []string
+	for _, airport := range airports {
+		if _, ok := covered[airport]; !ok {
+			kept = append(kept, airport)
+		}
+	}
+	return kept
+}
+
+// warnCoveredAirports logs a warning for every airport collapsed into its city
+// by withoutCoveredAirports.
+func warnCoveredAirports(log *slog.Logger, airports, cities []string) {
+	for airport, city := range coveredAirports(airports, cities) {
+		log.Warn("airport is covered by its city, searching the city only", "airport", airport, "city", city)
+	}
+}
@@ -0,0 +1,70 @@
+package cheapflight
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWarnAmbiguousCities(t *testing.T) {
+	tests := []struct {
+		cities   []string
+		wantWarn []string
+	}{
+		{cities: []string{"Springfield"}, wantWarn: []string{"city=Springfield"}},
+		{cities: []string{"albany", "denver"}, wantWarn: []string{"city=albany", "Albany, GA (ABY)"}},
+		{cities: []string{"denver"}},
+		{cities: nil},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		warnAmbiguousCities(slog.New(slog.NewTextHandler(&buf, nil)), tt.cities)
+		logged := buf.String()
+
+		if len(tt.wantWarn) == 0 {
+			if logged != "" {
+				t.Errorf("%q logged %q, want nothing", tt.cities, logged)
+			}
+			continue
+		}
+		if strings.Count(logged, "ambiguous city name") != 1 {
+			t.Errorf("%q logged %q, want one warning", tt.cities, logged)
+		}
+		for _, want := range tt.wantWarn {
+			if !strings.Contains(logged, want) {
+				t.Errorf("%q logged %q, want it to mention %q", tt.cities, logged, want)
+			}
+		}
+	}
+}
+
+func TestCoveredAirports(t *testing.T) {
+	tests := []struct {
+		src          string
+		wantAirports []string
+		wantCities   []string
+		wantWarn     string
+	}{
+		{src: "SFO,san francisco", wantCities: []string{"san francisco"}, wantWarn: "airport=SFO city=\"san francisco\""},
+		{src: "SFO,OAK,San Francisco", wantAirports: []string{"OAK"}, wantCities: []string{"San Francisco"}, wantWarn: "airport=SFO"},
+		{src: "SFO,Denver", wantAirports: []string{"SFO"}, wantCities: []string{"Denver"}},
+		{src: "SFO", wantAirports: []string{"SFO"}},
+	}
+	for _, tt := range tests {
+		args := route{Src: tt.src, Dst: "JFK"}.apply(routeArgs(), ",")
+		if strings.Join(args.SrcAirports, ",") != strings.Join(tt.wantAirports, ",") || strings.Join(args.SrcCities, ",") != strings.Join(tt.wantCities, ",") {
+			t.Errorf("%s searched airports %q and cities %q, want %q and %q", tt.src, args.SrcAirports, args.SrcCities, tt.wantAirports, tt.wantCities)
+		}
+
+		var buf bytes.Buffer
+		airports, cities := parseLocations(tt.src, ",")
+		warnCoveredAirports(slog.New(slog.NewTextHandler(&buf, nil)), airports, cities)
+		if warned := strings.Count(buf.String(), "airport is covered by its city"); warned != min(len(tt.wantWarn), 1) {
+			t.Errorf("%s logged %d warnings: %s", tt.src, warned, buf.String())
+		}
+		if !strings.Contains(buf.String(), tt.wantWarn) {
+			t.Errorf("%s logged %q, want it to mention %q", tt.src, buf.String(), tt.wantWarn)
+		}
+	}
+}
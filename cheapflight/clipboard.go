@@ -0,0 +1,43 @@
+package cheapflight
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// errClipboardUnavailable is returned by systemClipboard where no clipboard
+// command is installed or the platform has none.
+var errClipboardUnavailable = errors.New("no system clipboard available")
+
+// clipboardCommand is a command line that copies its standard input to the
+// clipboard.
+type clipboardCommand []string
+
+// writeClipboardCommands copies text with the first of commands that is
+// installed.
+func writeClipboardCommands(text string, commands ...clipboardCommand) error {
+	for _, command := range commands {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errClipboardUnavailable
+}
+
+// copyBestURL copies the booking link of the cheapest result with write, for
+// --copy. Results without a link are not copied.
+func copyBestURL(messages []Message, write func(string) error) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	url := cheapestMessage(messages).Url
+	if url == "" {
+		return nil
+	}
+	return write(url)
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd && !dragonfly
+
+package cheapflight
+
+func systemClipboard(string) error {
+	return errClipboardUnavailable
+}
@@ -0,0 +1,62 @@
+package cheapflight
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyBestURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		writeErr error
+		want     []string
+	}{
+		{name: "cheapest link", messages: renderMessages, want: []string{"https://example.com/a"}},
+		{name: "no results"},
+		{name: "cheapest has no link", messages: []Message{{Price: 250}, {Price: 310, Url: "https://example.com/b"}}},
+		{name: "write fails", messages: renderMessages, writeErr: errBoom, want: []string{"https://example.com/a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var copied []string
+			cfg := Config{clipboard: func(text string) error {
+				copied = append(copied, text)
+				return tt.writeErr
+			}}
+
+			err := copyBestURL(tt.messages, cfg.writeClipboard)
+			if !errors.Is(err, tt.writeErr) {
+				t.Errorf("error %v, want %v", err, tt.writeErr)
+			}
+			if len(copied) != len(tt.want) || (len(copied) == 1 && copied[0] != tt.want[0]) {
+				t.Errorf("copied %q, want %q", copied, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteClipboardCommands(t *testing.T) {
+	if err := writeClipboardCommands("x", clipboardCommand{"runway-no-such-clipboard"}); !errors.Is(err, errClipboardUnavailable) {
+		t.Errorf("without a clipboard command: error %v, want errClipboardUnavailable", err)
+	}
+
+	// The first installed command gets the text on its standard input.
+	path := filepath.Join(t.TempDir(), "clipboard")
+	err := writeClipboardCommands("https://example.com/a",
+		clipboardCommand{"runway-no-such-clipboard"},
+		clipboardCommand{"sh", "-c", `cat > "$0"`, path},
+	)
+	if err != nil {
+		t.Skipf("no sh to stand in for a clipboard: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "https://example.com/a" {
+		t.Errorf("clipboard got %q", b)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package cheapflight
+
+// systemClipboard tries Wayland's wl-copy before the X11 tools.
+func systemClipboard(text string) error {
+	return writeClipboardCommands(text,
+		clipboardCommand{"wl-copy"},
+		clipboardCommand{"xclip", "-selection", "clipboard"},
+		clipboardCommand{"xsel", "--clipboard", "--input"},
+	)
+}
@@ -0,0 +1,5 @@
+package cheapflight
+
+func systemClipboard(text string) error {
+	return writeClipboardCommands(text, clipboardCommand{"clip"})
+}
@@ -0,0 +1,116 @@
+package cheapflight
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// useColor reports whether output should be colorized according to --color.
+// auto colors only when results go to a terminal and NO_COLOR is not set.
+func (c Config) useColor() bool {
+	switch c.Color {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	return c.OutputFile == "" && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// fareColors colors results in the table and compact formats by how their price
+// compares with the price range of their query: green for deals, yellow within
+// nearDeal percent above the low end of the range, red more than expensive
+// percent above its high end.
+type fareColors struct {
+	nearDeal  float64
+	expensive float64
+}
+
+// fareColors returns nil when output is not colorized.
+func (c Config) fareColors() *fareColors {
+	if !c.useColor() {
+		return nil
+	}
+	return &fareColors{nearDeal: c.NearDealPercent, expensive: c.ExpensivePercent}
+}
+
+// color returns the ANSI color of m, or "" to leave it uncolored.
+func (c *fareColors) color(m Message) string {
+	if c == nil {
+		return ""
+	}
+	price := float64(m.Price)
+	switch r := m.PriceRange; {
+	case m.Deal || r != nil && price < r.Low:
+		return ansiGreen
+	case r == nil:
+		return ""
+	case price <= r.Low*(1+c.nearDeal/100):
+		return ansiYellow
+	case price > r.High*(1+c.expensive/100):
+		return ansiRed
+	}
+	return ""
+}
+
+// colorLine colors a rendered line of m, leaving it unchanged without a color.
+func (c *fareColors) colorLine(line string, m Message) string {
+	if color := c.color(m); color != "" {
+		return colorize(line, color)
+	}
+	return line
+}
+
+func colorize(s, color string) string {
+	return color + s + ansiReset
+}
+
+var jsonLine = regexp.MustCompile(`^(\s*)("(?:[^"\\]|\\.)*": )?(.*?)(,?)$`)
+
+// colorizeJSON highlights keys and scalar values of indented JSON line by line.
+func colorizeJSON(b []byte) []byte {
+	lines := strings.Split(string(b), "\n")
+	for i, line := range lines {
+		m := jsonLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		indent, key, value, comma := m[1], m[2], m[3], m[4]
+		if key != "" {
+			key = colorize(strings.TrimSuffix(key, ": "), ansiCyan) + ": "
+		}
+		switch {
+		case value == "" || strings.ContainsAny(value[:1], "{}[]"):
+		case value[0] == '"':
+			value = colorize(value, ansiGreen)
+		default:
+			value = colorize(value, ansiYellow)
+		}
+		lines[i] = indent + key + value + comma
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,109 @@
+package cheapflight
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// withTerminalStdout points os.Stdout at /dev/null for the rest of the test,
+// which like a terminal is a character device.
+func withTerminalStdout(t *testing.T) {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	if !isTerminal(devNull) {
+		t.Skipf("%s is not a character device", os.DevNull)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+func TestColorOnTerminal(t *testing.T) {
+	withTerminalStdout(t)
+	tests := []struct {
+		color     string
+		noColor   string
+		wantColor bool
+	}{
+		{color: colorAuto, wantColor: true},
+		{color: colorAuto, noColor: "1", wantColor: false},
+		{color: colorAlways, wantColor: true},
+		{color: colorNever, wantColor: false},
+	}
+	for _, tt := range tests {
+		for _, format := range []string{formatPrettyJSON, formatTable} {
+			t.Run(tt.color+" "+format+" NO_COLOR="+tt.noColor, func(t *testing.T) {
+				t.Setenv("NO_COLOR", tt.noColor)
+				cfg, err := ProcessFlags([]string{"--format=" + format, "--color=" + tt.color})
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				if err := cfg.renderer().Render(&buf, renderMessages); err != nil {
+					t.Fatal(err)
+				}
+				if hasColor := strings.Contains(buf.String(), "\x1b["); hasColor != tt.wantColor {
+					t.Errorf("output has colors %v, want %v:\n%q", hasColor, tt.wantColor, buf.String())
+				}
+			})
+		}
+	}
+}
+
+func TestFareColors(t *testing.T) {
+	priceRange := &flights.PriceRange{Low: 200, High: 400}
+	tests := []struct {
+		name      string
+		flags     []string
+		price     int
+		wantColor string
+	}{
+		{name: "below low", flags: []string{"--color=always"}, price: 199, wantColor: ansiGreen},
+		{name: "near deal", flags: []string{"--color=always"}, price: 220, wantColor: ansiYellow},
+		{name: "past near deal", flags: []string{"--color=always"}, price: 221},
+		{name: "wider near deal", flags: []string{"--color=always", "--near-deal-percent=20"}, price: 240, wantColor: ansiYellow},
+		{name: "expensive", flags: []string{"--color=always"}, price: 401, wantColor: ansiRed},
+		{name: "within expensive percent", flags: []string{"--color=always", "--expensive-percent=10"}, price: 440},
+		{name: "never", flags: []string{"--color=never"}, price: 199},
+	}
+	for _, tt := range tests {
+		for _, format := range [][]string{{"--format=table"}, {"--compact"}} {
+			t.Run(tt.name+" "+format[0], func(t *testing.T) {
+				cfg, err := ProcessFlags(append(format, tt.flags...))
+				if err != nil {
+					t.Fatal(err)
+				}
+				m := Message{Price: tt.price, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", PriceRange: priceRange}
+				var buf bytes.Buffer
+				if err := cfg.renderer().Render(&buf, []Message{m}); err != nil {
+					t.Fatal(err)
+				}
+
+				lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+				row := lines[len(lines)-1]
+				if tt.wantColor == "" {
+					if strings.Contains(buf.String(), "\x1b[") {
+						t.Errorf("output is colored: %q", buf.String())
+					}
+					return
+				}
+				if !strings.HasPrefix(row, tt.wantColor) || !strings.HasSuffix(row, ansiReset) {
+					t.Errorf("row %q is not colored %q", row, tt.wantColor)
+				}
+				if len(lines) > 1 && strings.Contains(lines[0], "\x1b[") {
+					t.Errorf("header is colored: %q", lines[0])
+				}
+			})
+		}
+	}
+}
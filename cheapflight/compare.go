@@ -0,0 +1,108 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/krisukox/google-flights-api/flights"
+	"golang.org/x/sync/errgroup"
+)
+
+var cabinClasses = []flights.Class{flights.Economy, flights.PremiumEconomy, flights.Business, flights.First}
+
+func className(class flights.Class) string {
+	switch class {
+	case flights.PremiumEconomy:
+		return "premium-economy"
+	case flights.Business:
+		return "business"
+	case flights.First:
+		return "first"
+	default:
+		return "economy"
+	}
+}
+
+// cabinComparison holds the cheapest price per departure date and cabin class.
+type cabinComparison map[string]map[flights.Class]float64
+
+// compareCabins runs the search once per cabin class, in parallel. A class
+// without offers is left out of the comparison, while any other failure stops
+// the searches of the remaining classes.
+func compareCabins(args flights.PriceGraphArgs, excludedAirline string, cfg Config) (cabinComparison, error) {
+	find := findOffersRange
+	if args.TripLength == fixedDates {
+		find = findOffersFixedDates
+	}
+
+	var mu sync.Mutex
+	comparison := make(cabinComparison)
+	g, ctx := errgroup.WithContext(context.Background())
+	for _, class := range cabinClasses {
+		class := class
+		g.Go(func() error {
+			classArgs := args
+			classArgs.Options.Class = class
+			set, err := find(ctx, cfg.session(), classArgs, excludedAirline, cfg)
+			if errors.Is(err, errNoOffers) {
+				cfg.logger().Info("no offers in cabin class", "class", className(class))
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", className(class), err)
+			}
+
+			byDate := make(map[string][]flights.FullOffer)
+			for _, o := range set.offers {
+				byDate[dateKey(o.StartDate)] = append(byDate[dateKey(o.StartDate)], o)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for date, offers := range byDate {
+				if best := cfg.bestOffer(offers, excludedAirline); best.Price != 0 {
+					if comparison[date] == nil {
+						comparison[date] = make(map[flights.Class]float64)
+					}
+					comparison[date][class] = best.Price
+				}
+			}
+			return nil
+		})
+	}
+	return comparison, g.Wait()
+}
+
+func (c cabinComparison) Render(w io.Writer) error {
+	var dates []string
+	for date := range c {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"DATE"}
+	for _, class := range cabinClasses {
+		header = append(header, strings.ToUpper(className(class)))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, date := range dates {
+		row := []string{date}
+		for _, class := range cabinClasses {
+			if price, ok := c[date][class]; ok {
+				row = append(row, fmt.Sprintf("%.0f", price))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,103 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestCompareCabins(t *testing.T) {
+	classPrice := map[flights.Class]float64{
+		flights.Economy:        200,
+		flights.PremiumEconomy: 450,
+		flights.Business:       1200,
+		flights.First:          3000,
+	}
+	api := &stubAPI{
+		priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+			return []flights.Offer{{StartDate: day1, Price: 1}, {StartDate: day2, Price: 1}}, nil
+		},
+		offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			price := classPrice[args.Options.Class]
+			if args.Date.Equal(day2) {
+				if args.Options.Class == flights.First {
+					return nil, nil, nil
+				}
+				price += 10
+			}
+			return []flights.FullOffer{offerOn(args.Date, price), offerOn(args.Date, price+50)}, nil, nil
+		},
+	}
+	cfg := withStub(Config{}, api)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, Options: roundTrip()}
+
+	comparison, err := compareCabins(args, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := comparison.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "DATE        ECONOMY  PREMIUM-ECONOMY  BUSINESS  FIRST\n" +
+		"2030-03-01  200      450              1200      3000\n" +
+		"2030-03-02  210      460              1210      -\n"
+	if buf.String() != want {
+		t.Errorf("comparison table:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestCompareCabinsFixedDates(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		if args.Options.Class == flights.First {
+			return nil, nil, nil
+		}
+		return []flights.FullOffer{offerOn(args.Date, 200*float64(args.Options.Class))}, nil, nil
+	}}
+	cfg := withStub(Config{}, api)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, Options: roundTrip()}
+
+	// A class without offers is a missing cell rather than failing the others.
+	comparison, err := compareCabins(args, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := comparison.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "DATE        ECONOMY  PREMIUM-ECONOMY  BUSINESS  FIRST\n" +
+		"2030-03-01  200      400              600       -\n"
+	if buf.String() != want {
+		t.Errorf("comparison table:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestCompareCabinsCancelsOnFailure(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		if args.Options.Class == flights.Economy {
+			return nil, nil, errBoom
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return []flights.FullOffer{offerOn(args.Date, 500)}, nil, nil
+		}
+	}}
+	cfg := withStub(Config{}, api)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, Options: roundTrip()}
+
+	start := time.Now()
+	if _, err := compareCabins(args, "", cfg); !errors.Is(err, errBoom) {
+		t.Errorf("compareCabins() error = %v, want the economy failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("compareCabins() took %s, want the other classes cancelled", elapsed)
+	}
+}
@@ -0,0 +1,689 @@
+package cheapflight
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+const (
+	childMinAge = 3
+	adultMinAge = 12
+)
+
+// dateInputFormats maps each --date-input-format value to its time layout.
+var dateInputFormats = map[string]string{
+	"mm-dd-yyyy": defaultDateFormat,
+	"yyyy-mm-dd": time.DateOnly,
+	"dd/mm/yyyy": "02/01/2006",
+}
+
+// Config holds the optional --flag arguments that follow the positional request arguments.
+type Config struct {
+	Lang        language.Tag
+	Currency    currency.Unit
+	Separator   string
+	Format      string
+	Color       string
+	OutputFile  string
+	SummaryOnly bool
+	// Sort orders the results: route, price or price-per-mile.
+	Sort  string
+	Quiet bool
+	// Anonymize strips booking links and coarsens dates to months in rendered output.
+	Anonymize bool
+	// IncludeSegments adds the flights of each offer to structured results.
+	IncludeSegments bool
+	// OutputTemplate replaces the text format with a Go template executed per result.
+	OutputTemplate *template.Template
+	// CompactFields prints the text format one line per result with these fields when set.
+	CompactFields  []string
+	RoutesFile     string
+	CheckpointFile string
+	LogFile        string
+	LogMaxSize     int64
+	WebhookURL     string
+	AlertFile      string
+	Notify         []string
+	NotifyMode     string
+	EmailTo        []string
+	AlertCooldown  time.Duration
+	SearchesDir    string
+
+	// DisplayCurrencies lists extra currencies every price is converted into with Rates.
+	DisplayCurrencies []currency.Unit
+	Rates             RateProvider
+
+	// DateInputFormat is the Go time layout of the date arguments, empty to
+	// accept mm-dd-yyyy or yyyy-mm-dd.
+	DateInputFormat string
+	// ExcludedDates holds the dateKey of every date that cannot be travelled on.
+	ExcludedDates map[string]bool
+	// ReturnStartDate and ReturnEndDate search returns in their own range
+	// instead of a fixed trip length when set.
+	ReturnStartDate time.Time
+	ReturnEndDate   time.Time
+	// DepartDays and ReturnDays restrict the weekdays trips may depart and return on.
+	DepartDays map[time.Weekday]bool
+	ReturnDays map[time.Weekday]bool
+	// MinAdvanceDays skips departures fewer than this many days from today.
+	MinAdvanceDays int
+	// Preset names a --preset replacing the date and trip length arguments.
+	Preset string
+	// OriginAirports replace the source argument with the airports found by
+	// --origin-near and --origin-radius.
+	OriginAirports []string
+
+	MaxOffersPerDate  int
+	MaxResults        int
+	PerPair           bool
+	FallbackToCity    bool
+	SplitLegs         bool
+	Reverse           bool
+	BudgetSplit       bool
+	MaxTotalCost      float64
+	BagFee            float64
+	ConfirmPrice      bool
+	ConfirmThreshold  float64
+	PreferredAirlines []string
+	AirportPreference []string
+	PriceTolerance    float64
+	OnlyRefundable    bool
+	MaxEmissions      float64
+	MinSeats          int
+	CompareCabins     bool
+	MinConnectionTime time.Duration
+
+	ExcludedFlightNumbers []string
+	Alliance              string
+	DealBasis             string
+	BaselinePrice         float64
+	MinDealCount          int
+	// NearDealPercent and ExpensivePercent are the thresholds of fareColors.
+	NearDealPercent  float64
+	ExpensivePercent float64
+
+	Travelers *flights.Travelers
+
+	RefreshInterval time.Duration
+	RequestTimeout  time.Duration
+	MaxWatchBackoff time.Duration
+	RetryOnEmpty    bool
+	MaxRetries      int
+	RetryBudget     int
+	LimitAPICalls   int
+	Seed            int64
+
+	// ContinueOnSerializeError reports offers without a booking link when the
+	// link cannot be built instead of failing the search.
+	ContinueOnSerializeError bool
+	// CanonicalURL strips tracking parameters from booking links, see canonicalURL.
+	CanonicalURL bool
+	// Copy copies the cheapest result's booking link to the clipboard.
+	Copy bool
+
+	Class *flights.Class
+	// ClassFallback are the cabin classes tried in order when the requested
+	// class finds no offers.
+	ClassFallback []flights.Class
+
+	// args is the command line of the request, os.Args when nil. Server
+	// requests carry their own so that concurrent requests do not share it.
+	args []string
+	// clock is what presets are resolved against, time.Now when nil.
+	clock func() time.Time
+	// clipboard is what --copy writes to, systemClipboard when nil.
+	clipboard func(string) error
+
+	sessions *sessionManager
+	// stream receives the offers of the current check with the ndjson format.
+	stream   *offerStream
+	backoff  *backoff
+	retries  *budget
+	apiCalls *budget
+	log      *slog.Logger
+	logFile  io.Closer
+}
+
+func ProcessFlags(args []string) (Config, error) {
+	var cfg Config
+	fs := flag.NewFlagSet("runway", flag.ContinueOnError)
+	fs.StringVar(&cfg.Separator, "sep", defaultSeparator, "separator between multiple source or destination airports and cities")
+	dateInputFormat := fs.String("date-input-format", "", "layout of the date arguments: mm-dd-yyyy, yyyy-mm-dd or dd/mm/yyyy (default accepts mm-dd-yyyy or yyyy-mm-dd)")
+	fs.StringVar(&cfg.Preset, "preset", "", "search fixed dates relative to today instead of the date arguments: "+strings.Join(presetNames(), ", "))
+	returnStartDate := fs.String("return-start-date", "", "earliest return date, searching every departure and return pair with --return-end-date")
+	returnEndDate := fs.String("return-end-date", "", "latest return date, used with --return-start-date")
+	departDays := fs.String("depart-days", "", "comma separated weekdays to depart on, e.g. fri,sat (default any day)")
+	returnDays := fs.String("return-days", "", "comma separated weekdays to return on, e.g. sun,mon (default any day)")
+	fs.IntVar(&cfg.MinAdvanceDays, "min-advance-days", 0, "skip departures fewer than this many days from today")
+	excludeDates := fs.String("exclude-dates", "", "comma separated dates or start..end ranges to never depart or return on")
+	currencyCode := fs.String("currency", "", "ISO 4217 currency for prices (defaults to the locale's currency, else USD)")
+	displayCurrencies := fs.String("display-currencies", "", "comma separated ISO 4217 currencies to also show each price in, e.g. USD,EUR,GBP")
+	ratesFile := fs.String("rates-file", "", "JSON exchange rates for --display-currencies instead of the ECB daily rates")
+	lang := fs.String("lang", "en", "language tag used to resolve city names (e.g. de)")
+	classFallback := fs.String("class-fallback", "", "comma separated cabin classes to try in order when the requested class finds no offers, e.g. premium-economy,economy")
+	outboundClass := fs.String("outbound-class", "", "cabin class for the outbound leg: economy, premium-economy, business or first")
+	returnClass := fs.String("return-class", "", "cabin class for the return leg, must match --outbound-class")
+	passengerAges := fs.String("passenger-ages", "", "comma separated passenger ages, overrides the travelers argument")
+	fs.StringVar(&cfg.Format, "format", formatText, "output format: "+strings.Join(formatNames(), ", "))
+	fs.StringVar(&cfg.Color, "color", colorAuto, "colorize output: auto (when writing to a terminal), always or never")
+	fs.StringVar(&cfg.RoutesFile, "routes-file", "", "search every \"SRC DST\" line of this file instead of the positional locations")
+	originNear := fs.String("origin-near", "", "search from every airport within --origin-radius of this city or airport instead of the source argument")
+	originRadius := fs.Float64("origin-radius", 100, "miles around --origin-near to search from")
+	fs.StringVar(&cfg.CheckpointFile, "checkpoint-file", "", "record finished routes here so an interrupted run resumes where it stopped")
+	fs.StringVar(&cfg.LogFile, "log-file", "", "append diagnostics to this file instead of stderr")
+	recordDir := fs.String("record", "", "save every flights API response to a fixture file in this directory")
+	replayDir := fs.String("replay", "", "serve flights API responses from the fixture files in this directory instead of calling the API")
+	fs.Int64Var(&cfg.LogMaxSize, "log-max-size", 0, "rotate --log-file to <file>.1 once it exceeds this many bytes (0 never rotates)")
+	fs.BoolVar(&cfg.IncludeSegments, "include-segments", false, "include each flight's airline, number, airports and times in json and ndjson results")
+	fs.BoolVar(&cfg.Anonymize, "anonymize", false, "remove booking links and flight details and show only the month of each date, for sharing output")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "do not show search progress on the terminal")
+	fs.BoolVar(&cfg.SummaryOnly, "summary-only", false, "only print the overall cheapest offer")
+	fs.StringVar(&cfg.Sort, "sort", sortRoute, "order of the results: route (as searched), price or price-per-mile")
+	outputTemplate := fs.String("output-template", "", "Go text/template printed per result with the text format, e.g. '{{.Price}} {{.StartDate}} {{.URL}}'")
+	compact := fs.Bool("compact", false, "print the text format as one line per result")
+	compactFields := fs.String("compact-fields", defaultCompactFields, "fields of --compact lines in order: route, dates, price, airline, deal, url")
+	fs.StringVar(&cfg.OutputFile, "output-file", "", "write results to this file instead of stdout")
+	fs.StringVar(&cfg.AlertFile, "alert-file", "alerted.json", "file remembering offers already alerted on (empty to alert every check)")
+	fs.DurationVar(&cfg.AlertCooldown, "alert-cooldown", 0, "after alerting on a route, send no further alerts for it for this long")
+	fs.StringVar(&cfg.SearchesDir, "searches-dir", "searches", "directory holding saved searches")
+	saveSearchName := fs.String("save-search", "", "save the flags of this request as the named search")
+	loadSearchName := fs.String("load-search", "", "apply the flags of the named saved search, overridden by flags on the command line")
+	fs.StringVar(&cfg.WebhookURL, "webhook-url", "", "also POST the formatted result to this webhook")
+	notifyList := fs.String("notify", "", "comma separated channels to alert through, in order: "+strings.Join(notifierNames(), ", ")+" (default the webhook when --webhook-url is set, then sms)")
+	fs.StringVar(&cfg.NotifyMode, "notify-mode", notifyModeAll, "all to alert through every --notify channel, fallback to stop at the first that succeeds")
+	emailTo := fs.String("email-to", "", "comma separated addresses the email channel of --notify alerts")
+	fs.DurationVar(&cfg.MinConnectionTime, "min-connection-time", 0, "drop offers with any connection shorter than this, e.g. 45m")
+	preferredAirlines := fs.String("preferred-airlines", "", "comma separated airlines, most preferred first, used to break price ties")
+	airportPreference := fs.String("airport-preference", "", "comma separated airports, most preferred first, winning over cheaper offers within --price-tolerance")
+	fs.Float64Var(&cfg.PriceTolerance, "price-tolerance", 0, "how much more a preferred airport's offer may cost and still win")
+	excludedFlightNumbers := fs.String("exclude-flight-numbers", "", "comma separated flight numbers, e.g. UA123,AA456, to drop offers containing")
+	fs.StringVar(&cfg.Alliance, "alliance", "", "only keep offers flown entirely by one alliance: "+strings.Join(allianceNames(), ", "))
+	fs.BoolVar(&cfg.OnlyRefundable, "only-refundable", false, "only keep refundable fares (not yet supported by the flights API)")
+	fs.Float64Var(&cfg.MaxEmissions, "max-emissions", 0, "drop offers emitting more than this many kg of CO2 (not yet supported by the flights API)")
+	fs.IntVar(&cfg.MinSeats, "min-seats", 0, "drop offers with fewer seats left at the fare (not yet supported by the flights API)")
+	fs.BoolVar(&cfg.CompareCabins, "compare-cabins", false, "print the cheapest price of every cabin class for each date and exit")
+	fs.BoolVar(&cfg.FallbackToCity, "fallback-to-city", false, "retry a search that found nothing using the city of each airport")
+	fs.Float64Var(&cfg.MaxTotalCost, "max-total-cost", 0, "drop offers whose price plus --bag-fee for every traveler exceeds this total budget (0 for no limit)")
+	fs.Float64Var(&cfg.BagFee, "bag-fee", 0, "bag fee per traveler for each direction flown, counted towards --max-total-cost")
+	fs.BoolVar(&cfg.BudgetSplit, "budget-split", false, "show the total fare and the fare per traveler of every result")
+	fs.BoolVar(&cfg.SplitLegs, "split-legs", false, "price round trips as two one-way tickets and report their combined price")
+	fs.BoolVar(&cfg.Reverse, "reverse", false, "also search every route from its destination back to its source")
+	fs.BoolVar(&cfg.ContinueOnSerializeError, "continue-on-serialize-error", true, "report offers without a booking link when it cannot be built (false fails the search)")
+	fs.BoolVar(&cfg.Copy, "copy", false, "copy the booking link of the cheapest result to the system clipboard")
+	fs.BoolVar(&cfg.CanonicalURL, "canonical-url", true, "strip tracking parameters from booking links and order the rest, so links are stable between runs")
+	fs.BoolVar(&cfg.ConfirmPrice, "confirm-price", false, "query each result again before reporting it and use the live price")
+	fs.Float64Var(&cfg.ConfirmThreshold, "confirm-threshold", 0, "warn and report the live price only when it differs from the searched one by more than this")
+	fs.BoolVar(&cfg.PerPair, "per-pair", false, "report the cheapest offer for every source and destination airport pair")
+	fs.IntVar(&cfg.MaxResults, "max-results", 0, "keep at most N of the best offers in memory while searching (0 for all)")
+	fs.IntVar(&cfg.MaxOffersPerDate, "max-offers-per-date", 0, "only evaluate the N cheapest offers for each date (0 for all)")
+	fs.Int64Var(&cfg.Seed, "seed", 0, "seed for retry jitter so runs are reproducible (0 seeds from the clock)")
+	fs.BoolVar(&cfg.RetryOnEmpty, "retry-on-empty", false, "retry dates the price graph priced but that returned no offers")
+	fs.IntVar(&cfg.MaxRetries, "max-retries", 3, "how many times to retry a date with --retry-on-empty")
+	fs.IntVar(&cfg.RetryBudget, "retry-budget", 0, "most retries made in each check, 0 for no limit")
+	fs.IntVar(&cfg.LimitAPICalls, "limit-api-calls", 0, "stop searching once this many flights API calls were made in a check, reporting the results found so far (0 for no limit)")
+	fs.DurationVar(&cfg.MaxWatchBackoff, "max-watch-backoff", 4*watchInterval, "longest wait between checks once several checks in a row have failed")
+	fs.DurationVar(&cfg.RefreshInterval, "refresh-interval", 0, "recreate the flights session after this long (0 to only refresh on auth failures)")
+	fs.DurationVar(&cfg.RequestTimeout, "request-timeout", 0, "give up on a single flights API call after this long (0 for no limit)")
+	fs.Float64Var(&cfg.NearDealPercent, "near-deal-percent", 10, "with --color, show table and compact results yellow within this percent above the low end of the price range")
+	fs.Float64Var(&cfg.ExpensivePercent, "expensive-percent", 0, "with --color, show table and compact results red more than this percent above the high end of the price range")
+	fs.Float64Var(&cfg.BaselinePrice, "baseline-price", 0, "mark any offer below this price as a deal regardless of --deal-basis")
+	fs.IntVar(&cfg.MinDealCount, "min-deal-count", 1, "only report a deal when the search found at least this many offers that are deals")
+	fs.StringVar(&cfg.DealBasis, "deal-basis", dealBasisLow, "price a deal must beat: low, high, typical or median-history")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *loadSearchName != "" {
+		if err := loadSearch(fs, cfg.SearchesDir, *loadSearchName); err != nil {
+			return Config{}, fmt.Errorf("loading search: %w", err)
+		}
+	}
+
+	if cfg.Separator == "" {
+		return Config{}, errors.New("--sep must not be empty")
+	}
+
+	if *dateInputFormat != "" {
+		layout, ok := dateInputFormats[*dateInputFormat]
+		if !ok {
+			return Config{}, fmt.Errorf("unknown --date-input-format %q, expected mm-dd-yyyy, yyyy-mm-dd or dd/mm/yyyy", *dateInputFormat)
+		}
+		cfg.DateInputFormat = layout
+	}
+
+	excludedDates, err := parseDateList(*excludeDates, cfg.DateInputFormat)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid --exclude-dates: %w", err)
+	}
+	cfg.ExcludedDates = excludedDates
+
+	if cfg.MinAdvanceDays < 0 {
+		return Config{}, errors.New("--min-advance-days must not be negative")
+	}
+	if cfg.DepartDays, err = parseWeekdays(*departDays); err != nil {
+		return Config{}, fmt.Errorf("invalid --depart-days: %w", err)
+	}
+	if cfg.ReturnDays, err = parseWeekdays(*returnDays); err != nil {
+		return Config{}, fmt.Errorf("invalid --return-days: %w", err)
+	}
+
+	if (*returnStartDate == "") != (*returnEndDate == "") {
+		return Config{}, errors.New("--return-start-date and --return-end-date must be given together")
+	}
+	if *returnStartDate != "" {
+		if cfg.ReturnStartDate, err = parseDate(*returnStartDate, cfg.DateInputFormat); err != nil {
+			return Config{}, fmt.Errorf("invalid --return-start-date: %w", err)
+		}
+		if cfg.ReturnEndDate, err = parseDate(*returnEndDate, cfg.DateInputFormat); err != nil {
+			return Config{}, fmt.Errorf("invalid --return-end-date: %w", err)
+		}
+		if cfg.ReturnEndDate.Before(cfg.ReturnStartDate) {
+			return Config{}, errors.New("--return-end-date must not be before --return-start-date")
+		}
+	}
+
+	tag, err := language.Parse(*lang)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid --lang %q: %w", *lang, err)
+	}
+	cfg.Lang = tag
+
+	cfg.Currency = localeCurrency()
+	if *currencyCode != "" {
+		cfg.Currency, err = currency.ParseISO(*currencyCode)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid --currency %q: %w", *currencyCode, err)
+		}
+	}
+
+	for _, code := range splitList(*displayCurrencies, ",") {
+		unit, err := currency.ParseISO(code)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid --display-currencies %q: %w", code, err)
+		}
+		cfg.DisplayCurrencies = append(cfg.DisplayCurrencies, unit)
+	}
+	cfg.Rates = &ecbRates{}
+	if *ratesFile != "" {
+		rates, err := loadRateTable(*ratesFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid --rates-file: %w", err)
+		}
+		cfg.Rates = rates
+	}
+
+	if *passengerAges != "" {
+		travelers, err := parsePassengerAges(*passengerAges)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Travelers = &travelers
+	}
+
+	class, err := parseLegClasses(*outboundClass, *returnClass)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Class = class
+	for _, name := range splitList(*classFallback, ",") {
+		class, err := parseClass(name)
+		if err != nil {
+			return Config{}, fmt.Errorf("--class-fallback: %w", err)
+		}
+		cfg.ClassFallback = append(cfg.ClassFallback, class)
+	}
+
+	if cfg.MaxOffersPerDate < 0 {
+		return Config{}, fmt.Errorf("--max-offers-per-date must not be negative")
+	}
+	if cfg.MaxWatchBackoff < watchInterval {
+		return Config{}, fmt.Errorf("--max-watch-backoff must be at least %s", watchInterval)
+	}
+	if cfg.MaxRetries < 0 {
+		return Config{}, fmt.Errorf("--max-retries must not be negative")
+	}
+	if *originNear != "" {
+		if cfg.RoutesFile != "" {
+			return Config{}, errors.New("--origin-near cannot be combined with --routes-file")
+		}
+		if *originRadius <= 0 {
+			return Config{}, errors.New("--origin-radius must be positive")
+		}
+		airports, err := nearbyAirports(*originNear, *originRadius)
+		if err != nil {
+			return Config{}, fmt.Errorf("--origin-near: %w", err)
+		}
+		cfg.OriginAirports = airports
+	}
+	cfg.EmailTo = splitList(*emailTo, ",")
+	if *notifyList != "" {
+		cfg.Notify = splitList(*notifyList, ",")
+		if err := cfg.checkNotifiers(cfg.Notify); err != nil {
+			return Config{}, fmt.Errorf("--notify: %w", err)
+		}
+	}
+	if cfg.NotifyMode != notifyModeAll && cfg.NotifyMode != notifyModeFallback {
+		return Config{}, fmt.Errorf("unknown --notify-mode %q, expected all or fallback", cfg.NotifyMode)
+	}
+	if cfg.AlertCooldown < 0 {
+		return Config{}, fmt.Errorf("--alert-cooldown must not be negative")
+	}
+	if cfg.RequestTimeout < 0 {
+		return Config{}, fmt.Errorf("--request-timeout must not be negative")
+	}
+	if cfg.RetryBudget < 0 {
+		return Config{}, fmt.Errorf("--retry-budget must not be negative")
+	}
+	if *recordDir != "" && *replayDir != "" {
+		return Config{}, fmt.Errorf("--record and --replay cannot be combined")
+	}
+	if cfg.LimitAPICalls < 0 {
+		return Config{}, fmt.Errorf("--limit-api-calls must not be negative")
+	}
+	if cfg.MaxResults < 0 {
+		return Config{}, fmt.Errorf("--max-results must not be negative")
+	}
+
+	if *preferredAirlines != "" {
+		cfg.PreferredAirlines = splitList(*preferredAirlines, ",")
+	}
+
+	for _, airport := range splitList(*airportPreference, ",") {
+		cfg.AirportPreference = append(cfg.AirportPreference, strings.ToUpper(airport))
+	}
+	if cfg.MinSeats < 0 {
+		return Config{}, errors.New("--min-seats must not be negative")
+	}
+	if cfg.MaxEmissions < 0 {
+		return Config{}, errors.New("--max-emissions must not be negative")
+	}
+	if cfg.ConfirmThreshold < 0 {
+		return Config{}, errors.New("--confirm-threshold must not be negative")
+	}
+	if cfg.PriceTolerance < 0 {
+		return Config{}, errors.New("--price-tolerance must not be negative")
+	}
+
+	for _, number := range splitList(*excludedFlightNumbers, ",") {
+		cfg.ExcludedFlightNumbers = append(cfg.ExcludedFlightNumbers, normalizeFlightNumber(number))
+	}
+
+	if cfg.Alliance != "" {
+		cfg.Alliance = strings.ToLower(cfg.Alliance)
+		if _, ok := alliances[cfg.Alliance]; !ok {
+			return Config{}, fmt.Errorf("unknown --alliance %q, expected one of %s", cfg.Alliance, strings.Join(allianceNames(), ", "))
+		}
+	}
+
+	if cfg.MinConnectionTime < 0 {
+		return Config{}, errors.New("--min-connection-time must not be negative")
+	}
+
+	if cfg.MinDealCount < 1 {
+		return Config{}, fmt.Errorf("--min-deal-count must be at least 1")
+	}
+	if cfg.MaxTotalCost < 0 || cfg.BagFee < 0 {
+		return Config{}, fmt.Errorf("--max-total-cost and --bag-fee must not be negative")
+	}
+	if cfg.NearDealPercent < 0 || cfg.ExpensivePercent < 0 {
+		return Config{}, fmt.Errorf("--near-deal-percent and --expensive-percent must not be negative")
+	}
+	if cfg.BaselinePrice < 0 {
+		return Config{}, fmt.Errorf("--baseline-price must not be negative")
+	}
+
+	switch cfg.DealBasis {
+	case dealBasisLow, dealBasisHigh, dealBasisTypical, dealBasisMedianHistory:
+	default:
+		return Config{}, fmt.Errorf("unknown --deal-basis %q", cfg.DealBasis)
+	}
+
+	switch cfg.Sort {
+	case sortRoute, sortPrice, sortPricePerMile:
+	default:
+		return Config{}, fmt.Errorf("unknown --sort %q, expected route, price or price-per-mile", cfg.Sort)
+	}
+
+	switch cfg.Color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return Config{}, fmt.Errorf("unknown --color %q, expected auto, always or never", cfg.Color)
+	}
+
+	if _, ok := renderers[cfg.Format]; !ok {
+		return Config{}, fmt.Errorf("unknown --format %q", cfg.Format)
+	}
+	if cfg.WebhookURL != "" && cfg.Format != formatText && !webhookFormats[cfg.Format] {
+		return Config{}, fmt.Errorf("--webhook-url posts JSON and cannot be combined with --format %s, use one of %s", cfg.Format, strings.Join(webhookFormatNames(), ", "))
+	}
+	if cfg.Format == formatSQLiteExport && cfg.OutputFile == "" {
+		return Config{}, fmt.Errorf("--format %s needs --output-file for the database", formatSQLiteExport)
+	}
+
+	if *outputTemplate != "" {
+		if cfg.Format != formatText {
+			return Config{}, fmt.Errorf("--output-template cannot be combined with --format %s", cfg.Format)
+		}
+		cfg.OutputTemplate, err = parseOutputTemplate(*outputTemplate)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid --output-template: %w", err)
+		}
+	}
+
+	if cfg.Preset != "" {
+		if _, ok := presets[cfg.Preset]; !ok {
+			return Config{}, fmt.Errorf("unknown --preset %q, expected one of %s", cfg.Preset, strings.Join(presetNames(), ", "))
+		}
+	}
+
+	if *saveSearchName != "" {
+		if err := saveSearch(fs, cfg.SearchesDir, *saveSearchName); err != nil {
+			return Config{}, fmt.Errorf("saving search: %w", err)
+		}
+	}
+
+	if *compact {
+		if cfg.Format != formatText || cfg.OutputTemplate != nil {
+			return Config{}, errors.New("--compact only applies to the text format without --output-template")
+		}
+		if cfg.CompactFields, err = parseCompactFields(*compactFields); err != nil {
+			return Config{}, fmt.Errorf("invalid --compact-fields: %w", err)
+		}
+	}
+
+	cfg.log = slog.Default()
+	if cfg.LogFile != "" {
+		f, err := openRotatingFile(cfg.LogFile, cfg.LogMaxSize)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.log = slog.New(slog.NewTextHandler(f, nil))
+		cfg.logFile = f
+	}
+
+	// The flights API does not expose fare conditions, so refundability cannot be filtered on.
+	if cfg.OnlyRefundable {
+		cfg.log.Warn("--only-refundable has no effect: the flights API does not report whether fares are refundable")
+	}
+	if cfg.MaxEmissions > 0 {
+		cfg.log.Warn("--max-emissions has no effect: the flights API does not report emissions estimates")
+	}
+	if cfg.MinSeats > 0 {
+		cfg.log.Warn("--min-seats has no effect: the flights API does not report seat availability")
+	}
+
+	cfg.backoff = newBackoff(cfg.Seed)
+	cfg.retries = newBudget(cfg.RetryBudget, cfg.log, "retry budget spent, no further calls are retried")
+	cfg.apiCalls = newBudget(cfg.LimitAPICalls, cfg.log, "API call limit reached, results are partial")
+	cfg.sessions = newSessionManager(cfg.RefreshInterval, cfg.RequestTimeout, cfg.backoff, cfg.retries, cfg.apiCalls, cfg.log)
+	cfg.sessions.fixtures = newFixtures(*recordDir, *replayDir)
+	return cfg, nil
+}
+
+// applyOptions overrides the search options configured by the positional arguments.
+func (c Config) applyOptions(options *flights.Options) {
+	options.Lang = c.Lang
+	if c.Currency != (currency.Unit{}) {
+		options.Currency = c.Currency
+	}
+	if c.Travelers != nil {
+		options.Travelers = *c.Travelers
+	}
+	if c.Class != nil {
+		options.Class = *c.Class
+	}
+}
+
+// parseLegClasses resolves the per-leg cabin flags. Google Flights applies one
+// class to the whole itinerary, so differing outbound and return classes are
+// rejected rather than silently searching with only one of them.
+func parseLegClasses(outbound, ret string) (*flights.Class, error) {
+	if outbound == "" && ret == "" {
+		return nil, nil
+	}
+
+	var classes []flights.Class
+	for _, name := range []string{outbound, ret} {
+		if name == "" {
+			continue
+		}
+		class, err := parseClass(name)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	if len(classes) == 2 && classes[0] != classes[1] {
+		return nil, errors.New("different outbound and return cabin classes are not supported by the flights API")
+	}
+	return &classes[0], nil
+}
+
+func parseClass(name string) (flights.Class, error) {
+	switch strings.ToLower(name) {
+	case "economy":
+		return flights.Economy, nil
+	case "premium-economy", "premium":
+		return flights.PremiumEconomy, nil
+	case "business":
+		return flights.Business, nil
+	case "first":
+		return flights.First, nil
+	}
+	return 0, fmt.Errorf("unknown cabin class %q", name)
+}
+
+// parsePassengerAges buckets ages into adults (12 and over), children (3 to 11)
+// and infants on lap (2 and under).
+func parsePassengerAges(ages string) (flights.Travelers, error) {
+	var travelers flights.Travelers
+	for _, a := range strings.Split(ages, ",") {
+		age, err := strconv.Atoi(strings.TrimSpace(a))
+		if err != nil || age < 0 {
+			return flights.Travelers{}, fmt.Errorf("invalid passenger age %q", a)
+		}
+
+		switch {
+		case age >= adultMinAge:
+			travelers.Adults++
+		case age >= childMinAge:
+			travelers.Children++
+		default:
+			travelers.InfantOnLap++
+		}
+	}
+	return travelers, nil
+}
+
+// Close releases the files opened for the config.
+func (c Config) Close() error {
+	if c.logFile == nil {
+		return nil
+	}
+	return c.logFile.Close()
+}
+
+func (c Config) retryDelay(attempt int) time.Duration {
+	if c.backoff == nil {
+		return backoffBase
+	}
+	return c.backoff.Delay(attempt)
+}
+
+func (c Config) requestArgs() []string {
+	if c.args == nil {
+		return os.Args
+	}
+	return c.args
+}
+
+func (c Config) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+func (c Config) writeClipboard(text string) error {
+	if c.clipboard == nil {
+		return systemClipboard(text)
+	}
+	return c.clipboard(text)
+}
+
+func (c Config) logger() *slog.Logger {
+	if c.log == nil {
+		return slog.Default()
+	}
+	return c.log
+}
+
+func (c Config) session() *sessionManager {
+	if c.sessions == nil {
+		return newSessionManager(c.RefreshInterval, c.RequestTimeout, newBackoff(c.Seed), c.retries, c.apiCalls, c.logger())
+	}
+	return c.sessions
+}
+
+func (c Config) renderer() Renderer {
+	if newRenderer, ok := renderers[c.Format]; ok {
+		return newRenderer(c)
+	}
+	return textRenderer{}
+}
+
+// output opens the destination for rendered results. The returned close
+// function must be called once the results are written.
+func (c Config) output() (io.Writer, func() error, error) {
+	if c.OutputFile == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(c.OutputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// newOfferStream returns the stream of offers for the ndjson format written to
+// w, the output of a check, or nil for other formats.
+func (c Config) newOfferStream(w io.Writer) *offerStream {
+	if c.Format != formatNDJSON || c.SummaryOnly {
+		return nil
+	}
+	return newOfferStream(w, c.IncludeSegments, c.Anonymize)
+}
@@ -0,0 +1,163 @@
+package cheapflight
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestParsePassengerAges(t *testing.T) {
+	tests := []struct {
+		ages    string
+		want    flights.Travelers
+		wantErr bool
+	}{
+		{ages: "34,8,2", want: flights.Travelers{Adults: 1, Children: 1, InfantOnLap: 1}},
+		{ages: "34, 34, 8, 1", want: flights.Travelers{Adults: 2, Children: 1, InfantOnLap: 1}},
+		{ages: "12,11,0", want: flights.Travelers{Adults: 1, Children: 1, InfantOnLap: 1}},
+		{ages: "34,-1", wantErr: true},
+		{ages: "34,eight", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parsePassengerAges(tt.ages)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePassengerAges(%q) error = %v, want error %v", tt.ages, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePassengerAges(%q) = %+v, want %+v", tt.ages, got, tt.want)
+		}
+	}
+}
+
+func TestLegClasses(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		want    flights.Class
+		wantErr bool
+	}{
+		{name: "class argument kept", want: flights.Economy},
+		{name: "outbound only", flags: []string{"--outbound-class=business"}, want: flights.Business},
+		{name: "return only", flags: []string{"--return-class=first"}, want: flights.First},
+		{name: "matching legs", flags: []string{"--outbound-class=premium-economy", "--return-class=premium-economy"}, want: flights.PremiumEconomy},
+		{name: "differing legs", flags: []string{"--outbound-class=economy", "--return-class=business"}, wantErr: true},
+		{name: "unknown class", flags: []string{"--return-class=steerage"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags(tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessFlags(%q) error = %v, want error %v", tt.flags, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			api := &stubAPI{
+				priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+					return []flights.Offer{{StartDate: day1, ReturnDate: day1.AddDate(0, 0, 3), Price: 100}}, nil
+				},
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+				},
+			}
+			cfg = withStub(cfg, api)
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+			args.Options.Class = flights.Economy
+			cfg.applyOptions(&args.Options)
+			if _, err := SearchOffers(args, "", cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, call := range api.priceGraphCalls {
+				if call.Options.Class != tt.want {
+					t.Errorf("GetPriceGraph Class = %v, want %v", call.Options.Class, tt.want)
+				}
+			}
+			for _, call := range api.offerCalls {
+				if call.Options.Class != tt.want {
+					t.Errorf("GetOffers Class = %v, want %v", call.Options.Class, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOnlyRefundable(t *testing.T) {
+	tests := []struct {
+		flags    []string
+		wantWarn bool
+	}{
+		{flags: nil, wantWarn: false},
+		{flags: []string{"--only-refundable"}, wantWarn: true},
+	}
+	for _, tt := range tests {
+		logFile := filepath.Join(t.TempDir(), "runway.log")
+		cfg, err := ProcessFlags(append(tt.flags, "--log-file="+logFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Close()
+
+		// The flights API has no fare conditions, so refundable and
+		// non-refundable offers cannot be told apart and all are kept.
+		offers := []flights.FullOffer{offerOn(day1, 100), offerOn(day1, 200)}
+		if kept := cfg.filterOffers(offers, roundTrip(), ""); len(kept) != len(offers) {
+			t.Errorf("%q kept %d of %d offers", tt.flags, len(kept), len(offers))
+		}
+		logged, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if warned := strings.Contains(string(logged), "--only-refundable has no effect"); warned != tt.wantWarn {
+			t.Errorf("%q warned %v, want %v", tt.flags, warned, tt.wantWarn)
+		}
+	}
+}
+
+func TestUnsupportedFilters(t *testing.T) {
+	tests := []struct {
+		flags    []string
+		wantErr  bool
+		wantWarn string
+	}{
+		{flags: nil},
+		{flags: []string{"--max-emissions=250"}, wantWarn: "--max-emissions has no effect"},
+		{flags: []string{"--max-emissions=-1"}, wantErr: true},
+		{flags: []string{"--min-seats=4"}, wantWarn: "--min-seats has no effect"},
+		{flags: []string{"--min-seats=-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		logFile := filepath.Join(t.TempDir(), "runway.log")
+		cfg, err := ProcessFlags(append(tt.flags, "--log-file="+logFile))
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%q: error %v, want error %v", tt.flags, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		cfg.Close()
+
+		// Offers carry neither emissions estimates nor seat counts, so
+		// the filters cannot judge them and all are kept.
+		offers := []flights.FullOffer{offerOn(day1, 100), offerOn(day1, 200)}
+		if kept := cfg.filterOffers(offers, roundTrip(), ""); len(kept) != len(offers) {
+			t.Errorf("%q kept %d of %d offers", tt.flags, len(kept), len(offers))
+		}
+		logged, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.wantWarn == "" && strings.Contains(string(logged), "has no effect") {
+			t.Errorf("%q warned:\n%s", tt.flags, logged)
+		}
+		if !strings.Contains(string(logged), tt.wantWarn) {
+			t.Errorf("%q did not warn %q:\n%s", tt.flags, tt.wantWarn, logged)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// confirmPrice queries the offers for o's exact dates and airports again and
+// returns the live price of the same flights, or the cheapest price when the
+// same flights are no longer offered. Offers flown by excludedAirline are left
+// out as in the search.
+func confirmPrice(session *sessionManager, o flights.FullOffer, options flights.Options, excludedAirline string, cfg Config) (float64, error) {
+	offers, _, err := session.GetOffers(
+		context.Background(),
+		flights.Args{
+			Date:        calendarDate(o.StartDate),
+			ReturnDate:  calendarDate(returnDate(o.ReturnDate, o.StartDate, options)),
+			SrcAirports: []string{o.SrcAirportCode},
+			DstAirports: []string{o.DstAirportCode},
+			Options:     options,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	offers = cfg.filterOffers(offers, options, excludedAirline)
+	for _, c := range offers {
+		if c.Price != 0 && sameFlights(c, o) {
+			return c.Price, nil
+		}
+	}
+	if best := cfg.bestOffer(offers, excludedAirline); best.Price != 0 {
+		return best.Price, nil
+	}
+	return 0, errors.New("no offers left when confirming the price")
+}
+
+// sameFlights reports whether a and b fly the same flight numbers.
+func sameFlights(a, b flights.FullOffer) bool {
+	if len(a.Flight) != len(b.Flight) {
+		return false
+	}
+	for i := range a.Flight {
+		if normalizeFlightNumber(a.Flight[i].FlightNumber) != normalizeFlightNumber(b.Flight[i].FlightNumber) {
+			return false
+		}
+	}
+	return true
+}
+
+// confirmMessage re-prices m with --confirm-price. When the live price differs
+// from the searched one by more than --confirm-threshold, m takes the live price
+// and keeps the searched one in QuotedPrice.
+func confirmMessage(m *Message, session *sessionManager, o flights.FullOffer, options flights.Options, excludedAirline string, cfg Config) {
+	confirmed, err := confirmPrice(session, o, options, excludedAirline, cfg)
+	if err != nil {
+		cfg.logger().Warn("could not confirm price", "price", m.Price, "src", m.Src, "dst", m.Dst, "err", err)
+		return
+	}
+	if math.Abs(confirmed-o.Price) <= cfg.ConfirmThreshold {
+		return
+	}
+
+	cfg.logger().Warn("confirmed price differs from search", "quoted", m.Price, "confirmed", int(confirmed), "src", m.Src, "dst", m.Dst, "start", m.Start)
+	m.QuotedPrice = m.Price
+	m.Price = int(confirmed)
+}
@@ -0,0 +1,83 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestConfirmPrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  float64
+		live       float64
+		wantPrice  int
+		wantQuoted int
+		wantWarn   bool
+	}{
+		{name: "price went up", threshold: 10, live: 290, wantPrice: 290, wantQuoted: 250, wantWarn: true},
+		{name: "within the threshold", threshold: 50, live: 290, wantPrice: 250},
+		{name: "unchanged", live: 250, wantPrice: 250},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				if calls.Add(1) == 1 {
+					return []flights.FullOffer{offerOn(args.Date, 250)}, nil, nil
+				}
+				return []flights.FullOffer{offerOn(args.Date, tt.live)}, nil, nil
+			}}
+			cfg := withStub(Config{ConfirmPrice: true, ConfirmThreshold: tt.threshold}, api)
+			var logged bytes.Buffer
+			cfg.log = slog.New(slog.NewTextHandler(&logged, nil))
+
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+			messages, err := SearchOffers(args, "", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls.Load() != 2 {
+				t.Errorf("made %d offers calls, want a search and a confirmation", calls.Load())
+			}
+			if m := messages[0]; m.Price != tt.wantPrice || m.QuotedPrice != tt.wantQuoted {
+				t.Errorf("price %d quoted %d, want %d quoted %d", m.Price, m.QuotedPrice, tt.wantPrice, tt.wantQuoted)
+			}
+			if warned := strings.Contains(logged.String(), "confirmed price differs from search"); warned != tt.wantWarn {
+				t.Errorf("warned %v, want %v:\n%s", warned, tt.wantWarn, logged.String())
+			}
+		})
+	}
+}
+
+func TestConfirmPriceExcludedAirline(t *testing.T) {
+	flight := func(airline, number string, price float64) flights.FullOffer {
+		o := flownBy(airline, price)
+		o.Flight[0].FlightNumber = number
+		return o
+	}
+	var calls atomic.Int32
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		if calls.Add(1) == 1 {
+			return []flights.FullOffer{flight("Delta", "DL 1", 250)}, nil, nil
+		}
+		// The searched flight sold out, leaving a cheaper one on the excluded airline.
+		return []flights.FullOffer{flight("Spirit", "NK 2", 150), flight("Alaska", "AS 3", 280)}, nil, nil
+	}}
+	cfg := withStub(Config{ConfirmPrice: true}, api)
+
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+	messages, err := SearchOffers(args, "Spirit", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := messages[0]; m.Price != 280 || m.QuotedPrice != 250 {
+		t.Errorf("price %d quoted %d, want 280 quoted 250", m.Price, m.QuotedPrice)
+	}
+}
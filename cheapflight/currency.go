@@ -0,0 +1,40 @@
+package cheapflight
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// localeCurrency infers a currency from the locale environment, checked in
+// POSIX precedence order (LC_ALL, LC_MONETARY, LANG), falling back to USD.
+func localeCurrency() currency.Unit {
+	for _, env := range []string{"LC_ALL", "LC_MONETARY", "LANG"} {
+		if unit, ok := currencyForLocale(os.Getenv(env)); ok {
+			return unit
+		}
+	}
+	return currency.USD
+}
+
+// currencyForLocale maps a POSIX locale such as de_DE.UTF-8 to its region's currency.
+func currencyForLocale(locale string) (currency.Unit, bool) {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return currency.Unit{}, false
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return currency.Unit{}, false
+	}
+	region, confidence := tag.Region()
+	if confidence == language.No {
+		return currency.Unit{}, false
+	}
+	return currency.FromRegion(region)
+}
@@ -0,0 +1,35 @@
+package cheapflight
+
+import (
+	"testing"
+
+	"golang.org/x/text/currency"
+)
+
+func TestLocaleCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		lcAll    string
+		monetary string
+		lang     string
+		want     currency.Unit
+	}{
+		{name: "LC_MONETARY de_DE", monetary: "de_DE.UTF-8", want: currency.EUR},
+		{name: "LANG en_GB", lang: "en_GB.UTF-8", want: currency.GBP},
+		{name: "LC_MONETARY over LANG", monetary: "ja_JP", lang: "en_US.UTF-8", want: currency.JPY},
+		{name: "LC_ALL over LC_MONETARY", lcAll: "fr_CH", monetary: "de_DE", want: currency.CHF},
+		{name: "C locale", lang: "C", want: currency.USD},
+		{name: "unset", want: currency.USD},
+		{name: "language only", lang: "de", want: currency.EUR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_MONETARY", tt.monetary)
+			t.Setenv("LANG", tt.lang)
+			if got := localeCurrency(); got != tt.want {
+				t.Errorf("localeCurrency() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
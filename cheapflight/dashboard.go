@@ -0,0 +1,63 @@
+package cheapflight
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// dashboardRefresh is how often the dashboard page reloads itself.
+const dashboardRefresh = 60 * time.Second
+
+// dashboardRoute is the cheapest result of a route's most recent check.
+type dashboardRoute struct {
+	Route string
+	Message
+	Updated time.Time
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"price":      compactPrice,
+	"dealStatus": Message.dealStatus,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>Runway fares</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 1em; border-bottom: 1px solid #ddd; text-align: left; }
+tr.deal td { background: #e6f4ea; }
+</style>
+</head>
+<body>
+<h1>Current best fares</h1>
+{{if .Routes}}<table>
+<tr><th>Route</th><th>Price</th><th>Depart</th><th>Return</th><th>Airline</th><th>Deal</th><th>Checked</th><th></th></tr>
+{{range .Routes}}<tr{{if .Deal}} class="deal"{{end}}><td>{{.Route}}</td><td>{{price .Message}}</td><td>{{.Start}}</td><td>{{.End}}</td><td>{{.Airline}}</td><td>{{dealStatus .Message}}</td><td>{{.Updated.Format "Jan 2 15:04"}}</td><td>{{if .Url}}<a href="{{.Url}}">Book</a>{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No checks have finished yet.</p>{{end}}
+</body>
+</html>
+`))
+
+// ServeDashboard serves an HTML page listing the cheapest fare of the latest
+// check of every watched route. The page reloads itself every minute.
+func ServeDashboard(w http.ResponseWriter, r *http.Request) {
+	latest.mu.Lock()
+	routes := make([]dashboardRoute, 0, len(latest.routes))
+	for _, route := range latest.routes {
+		routes = append(routes, route)
+	}
+	latest.mu.Unlock()
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Route < routes[j].Route })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	dashboardTemplate.Execute(w, struct {
+		Refresh int
+		Routes  []dashboardRoute
+	}{int(dashboardRefresh.Seconds()), routes})
+}
@@ -0,0 +1,95 @@
+package cheapflight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// withLatest clears the results recorded for the server for the rest of the test.
+func withLatest(t *testing.T) {
+	t.Helper()
+	latest.mu.Lock()
+	saved, savedRoutes := latest.messages, latest.routes
+	latest.messages, latest.routes = nil, nil
+	latest.mu.Unlock()
+	t.Cleanup(func() {
+		latest.mu.Lock()
+		latest.messages, latest.routes = saved, savedRoutes
+		latest.mu.Unlock()
+	})
+}
+
+func TestServeDashboard(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks [][]Message
+		want   []string
+		absent []string
+	}{
+		{name: "no checks", want: []string{"No checks have finished yet."}},
+		{
+			name:   "latest price of each route",
+			checks: [][]Message{{{Price: 300, Src: "SFO", Dst: "JFK", route: "SFO → JFK"}}, {{Price: 250, Src: "SFO", Dst: "JFK", route: "SFO → JFK", Url: "https://example.com/a"}, {Price: 310, Src: "OAK", Dst: "EWR"}}},
+			want:   []string{"<td>OAK → EWR</td><td>$310</td>", "<td>SFO → JFK</td><td>$250</td>", `<a href="https://example.com/a">Book</a>`},
+			absent: []string{"$300"},
+		},
+		{
+			name:   "cheapest result of a check",
+			checks: [][]Message{{{Price: 280, Src: "SFO", Dst: "JFK", route: "SFO → JFK"}, {Price: 260, Src: "SFO", Dst: "JFK", route: "SFO → JFK"}}},
+			want:   []string{"<td>SFO → JFK</td><td>$260</td>"},
+			absent: []string{"$280"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withLatest(t)
+			for _, messages := range tt.checks {
+				recordLatest(messages)
+			}
+
+			rec := httptest.NewRecorder()
+			ServeDashboard(rec, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+			body := rec.Body.String()
+
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Errorf("Content-Type %q, want text/html", ct)
+			}
+			if !strings.Contains(body, `<meta http-equiv="refresh" content="60">`) {
+				t.Errorf("page does not refresh itself:\n%s", body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("page does not contain %q:\n%s", want, body)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(body, absent) {
+					t.Errorf("page contains stale %q:\n%s", absent, body)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardAnonymized(t *testing.T) {
+	withLatest(t)
+	m := Message{Price: 250, Src: "SFO", Dst: "JFK", route: "SFO → JFK", Start: "2030-03-01", End: "2030-03-04", Url: "https://example.com/a"}
+	m.departure, m.returnDate = day1, day4
+	recordCheck([]Message{m}, Config{Anonymize: true})
+
+	for _, serve := range []func(http.ResponseWriter, *http.Request){ServeDashboard, func(w http.ResponseWriter, r *http.Request) { RenderLatestBadge(w) }} {
+		rec := httptest.NewRecorder()
+		serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		body := rec.Body.String()
+		if !strings.Contains(body, "250") {
+			t.Errorf("page does not show the price:\n%s", body)
+		}
+		for _, absent := range []string{"example.com", "2030-03-01", "2030-03-04"} {
+			if strings.Contains(body, absent) {
+				t.Errorf("anonymized page contains %q:\n%s", absent, body)
+			}
+		}
+	}
+}
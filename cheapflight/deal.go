@@ -0,0 +1,95 @@
+package cheapflight
+
+import (
+	"sort"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+const (
+	dealBasisLow           = "low"
+	dealBasisHigh          = "high"
+	dealBasisTypical       = "typical"
+	dealBasisMedianHistory = "median-history"
+)
+
+// isDeal reports whether price counts as a deal under basis. The low, high and
+// typical bases compare against the price range Google Flights reports for the
+// offer's date, median-history compares against the best prices seen in earlier
+// cycles of the same request.
+func isDeal(price float64, priceRange *flights.PriceRange, basis string, history []float64) bool {
+	if basis == dealBasisMedianHistory {
+		if len(history) == 0 {
+			return false
+		}
+		return price < median(history)
+	}
+
+	if priceRange == nil {
+		return false
+	}
+
+	switch basis {
+	case dealBasisHigh:
+		return price < priceRange.High
+	case dealBasisTypical:
+		return price < (priceRange.Low+priceRange.High)/2
+	default:
+		return price < priceRange.Low
+	}
+}
+
+// dealKnown reports whether isDeal had the data it needs under basis: a price
+// range for the date, or earlier prices for median-history.
+func dealKnown(priceRange *flights.PriceRange, basis string, history []float64) bool {
+	if basis == dealBasisMedianHistory {
+		return len(history) > 0
+	}
+	return priceRange != nil
+}
+
+// dealCandidate is the price of one searched offer and the price range of the
+// query that found it, kept with --min-deal-count to count the deals a search
+// turned up.
+type dealCandidate struct {
+	price      float64
+	priceRange *flights.PriceRange
+}
+
+func dealCandidates(set offerSet) []dealCandidate {
+	var candidates []dealCandidate
+	for _, o := range set.offers {
+		if o.Price != 0 {
+			candidates = append(candidates, dealCandidate{price: o.Price, priceRange: set.priceRanges[tripKey(o.StartDate, o.ReturnDate)]})
+		}
+	}
+	return candidates
+}
+
+// countDeals counts the candidates that are deals by the same rules as a result.
+func countDeals(candidates []dealCandidate, basis string, baseline float64, history []float64) int {
+	n := 0
+	for _, c := range candidates {
+		if belowBaseline(c.price, baseline) || isDeal(c.price, c.priceRange, basis, history) {
+			n++
+		}
+	}
+	return n
+}
+
+// belowBaseline reports whether price beats the user's own idea of a good
+// fare. A baseline of 0 disables the check.
+func belowBaseline(price, baseline float64) bool {
+	return baseline > 0 && price < baseline
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
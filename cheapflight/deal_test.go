@@ -0,0 +1,197 @@
+package cheapflight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestIsDeal(t *testing.T) {
+	priceRange := &flights.PriceRange{Low: 200, High: 400}
+	history := []float64{350, 250, 300}
+	tests := []struct {
+		basis      string
+		price      float64
+		priceRange *flights.PriceRange
+		history    []float64
+		want       bool
+	}{
+		{dealBasisLow, 199, priceRange, nil, true},
+		{dealBasisLow, 200, priceRange, nil, false},
+		{dealBasisHigh, 399, priceRange, nil, true},
+		{dealBasisHigh, 400, priceRange, nil, false},
+		{dealBasisTypical, 299, priceRange, nil, true},
+		{dealBasisTypical, 300, priceRange, nil, false},
+		{dealBasisMedianHistory, 299, nil, history, true},
+		{dealBasisMedianHistory, 300, nil, history, false},
+		{dealBasisMedianHistory, 1, priceRange, nil, false},
+		{dealBasisLow, 1, nil, history, false},
+	}
+	for _, tt := range tests {
+		if got := isDeal(tt.price, tt.priceRange, tt.basis, tt.history); got != tt.want {
+			t.Errorf("isDeal(%v, %v, %s, %v) = %v, want %v", tt.price, tt.priceRange, tt.basis, tt.history, got, tt.want)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{[]float64{3}, 3},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		if got := median(tt.values); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestBaselinePrice(t *testing.T) {
+	priceRange := &flights.PriceRange{Low: 200, High: 400}
+	tests := []struct {
+		name     string
+		price    float64
+		baseline float64
+		want     bool
+	}{
+		{name: "below baseline above low", price: 250, baseline: 300, want: true},
+		{name: "at baseline", price: 300, baseline: 300, want: false},
+		{name: "below low without baseline", price: 150, want: true},
+		{name: "above low without baseline", price: 250, want: false},
+		{name: "below both", price: 150, baseline: 300, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []dealCandidate{{price: tt.price, priceRange: priceRange}}
+			want := 0
+			if tt.want {
+				want = 1
+			}
+			if got := countDeals(candidates, dealBasisLow, tt.baseline, nil); got != want {
+				t.Errorf("countDeals(%v, baseline %v) = %d, want %d", tt.price, tt.baseline, got, want)
+			}
+		})
+	}
+}
+
+func TestDealPerTrip(t *testing.T) {
+	// A quote is the price and range the offers query of one trip returns.
+	type quote struct {
+		price      float64
+		priceRange flights.PriceRange
+	}
+	cheap := flights.PriceRange{Low: 100, High: 200}
+	dear := flights.PriceRange{Low: 300, High: 400}
+	tests := []struct {
+		name      string
+		cfg       Config
+		quotes    map[string]quote
+		wantTrip  string
+		wantRange flights.PriceRange
+		wantDeal  bool
+	}{
+		{
+			name:      "cheapest date has the higher range",
+			quotes:    map[string]quote{tripKey(day1, day4): {300, cheap}, tripKey(day2, day2.AddDate(0, 0, 3)): {250, dear}},
+			wantTrip:  tripKey(day2, day2.AddDate(0, 0, 3)),
+			wantRange: dear,
+			wantDeal:  true,
+		},
+		{
+			name:      "cheapest date has the lower range",
+			quotes:    map[string]quote{tripKey(day1, day4): {250, cheap}, tripKey(day2, day2.AddDate(0, 0, 3)): {300, dear}},
+			wantTrip:  tripKey(day1, day4),
+			wantRange: cheap,
+		},
+		{
+			name:      "same departure, different returns",
+			cfg:       Config{ReturnStartDate: day3, ReturnEndDate: day4},
+			quotes:    map[string]quote{tripKey(day1, day3): {300, cheap}, tripKey(day1, day4): {250, dear}, tripKey(day2, day3): {350, cheap}, tripKey(day2, day4): {350, cheap}},
+			wantTrip:  tripKey(day1, day4),
+			wantRange: dear,
+			wantDeal:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{
+				priceGraph: pricedEveryDay,
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					q, ok := tt.quotes[tripKey(args.Date, args.ReturnDate)]
+					if !ok {
+						return nil, nil, nil
+					}
+					o := flights.FullOffer{Offer: flights.Offer{StartDate: args.Date, ReturnDate: args.ReturnDate, Price: q.price}, SrcAirportCode: "SFO", DstAirportCode: "JFK"}
+					return []flights.FullOffer{o}, &q.priceRange, nil
+				},
+			}
+			messages, err := searchRoutes([]route{{Src: "SFO", Dst: "JFK"}}, routeArgs(), "", withStub(tt.cfg, api), make(map[route][]float64), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("got %d results, want 1", len(messages))
+			}
+
+			m := messages[0]
+			if got := tripKey(m.departure, m.returnDate); got != tt.wantTrip {
+				t.Errorf("best trip %s, want %s", got, tt.wantTrip)
+			}
+			if m.PriceRange == nil || *m.PriceRange != tt.wantRange {
+				t.Errorf("judged against range %v, want the range of its own trip %v", m.PriceRange, tt.wantRange)
+			}
+			if m.Deal != tt.wantDeal {
+				t.Errorf("Deal %v, want %v", m.Deal, tt.wantDeal)
+			}
+		})
+	}
+}
+
+func TestMinDealCount(t *testing.T) {
+	// Offers under 200 are deals against the range of every date.
+	priceRange := &flights.PriceRange{Low: 200, High: 400}
+	tests := []struct {
+		name     string
+		minDeals int
+		day2     float64
+		wantDeal bool
+	}{
+		{name: "single deal under a threshold of 2", minDeals: 2, day2: 300, wantDeal: false},
+		{name: "single deal under the default threshold", minDeals: 1, day2: 300, wantDeal: true},
+		{name: "two deals under a threshold of 2", minDeals: 2, day2: 190, wantDeal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{fmt.Sprintf("--min-deal-count=%d", tt.minDeals)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &stubAPI{
+				priceGraph: pricedEveryDay,
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					price := 150.0
+					if args.Date.Equal(day2) {
+						price = tt.day2
+					}
+					return []flights.FullOffer{offerOn(args.Date, price)}, priceRange, nil
+				},
+			}
+			messages, err := searchRoutes([]route{{Src: "SFO", Dst: "JFK"}}, routeArgs(), "", withStub(cfg, api), make(map[route][]float64), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 || messages[0].Price != 150 {
+				t.Fatalf("got %+v, want the 150 offer", messages)
+			}
+			if messages[0].Deal != tt.wantDeal {
+				t.Errorf("Deal %v, want %v", messages[0].Deal, tt.wantDeal)
+			}
+		})
+	}
+}
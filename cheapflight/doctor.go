@@ -0,0 +1,112 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const googleURL = "https://www.google.com"
+
+// doctorCheck is one step of the doctor command. run returns a detail to show
+// when the step passes.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// doctorChecks go from the proxy settings up to creating a Google Flights
+// session, the steps sessionError points at.
+var doctorChecks = []doctorCheck{
+	{name: "proxy", run: checkProxy},
+	{name: "reach " + googleURL, run: checkGoogle},
+	{name: "create a Google Flights session", run: checkSession},
+}
+
+// Doctor runs each of doctorChecks, writing whether it passed to w, and
+// returns an error if any failed. It backs the doctor command, args being its
+// flags.
+func Doctor(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 30*time.Second, "time allowed for each check")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("doctor takes no arguments, got %q", fs.Args())
+	}
+
+	failed := 0
+	for _, check := range doctorChecks {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		detail, err := check.run(ctx)
+		cancel()
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(w, "FAIL  %s: %v\n", check.name, err)
+		case detail != "":
+			fmt.Fprintf(w, "ok    %s: %s\n", check.name, detail)
+		default:
+			fmt.Fprintf(w, "ok    %s\n", check.name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(doctorChecks))
+	}
+	return nil
+}
+
+// checkProxy reports the proxy HTTPS_PROXY or HTTP_PROXY route Google requests
+// through.
+func checkProxy(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleURL, nil)
+	if err != nil {
+		return "", err
+	}
+	proxy, err := http.ProxyFromEnvironment(req)
+	if err != nil {
+		return "", err
+	}
+	if proxy == nil {
+		return "none set, connecting directly", nil
+	}
+	return proxy.Redacted(), nil
+}
+
+func checkGoogle(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", errors.New("rate limited, wait a few minutes before retrying")
+	}
+	return resp.Status, nil
+}
+
+// checkSession creates a session as the searches do. The flights client does
+// not take a context, so a session still being created when ctx is done is
+// left behind.
+func checkSession(ctx context.Context) (string, error) {
+	done := make(chan error, 1)
+	go func() {
+		_, err := newFlightsSession()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return "", err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,32 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	saved := doctorChecks
+	t.Cleanup(func() { doctorChecks = saved })
+	doctorChecks = []doctorCheck{
+		{name: "proxy", run: func(ctx context.Context) (string, error) { return "none set", nil }},
+		{name: "reach google", run: func(ctx context.Context) (string, error) { return "", errBoom }},
+		{name: "create a session", run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}
+
+	var buf bytes.Buffer
+	err := Doctor(&buf, []string{"--timeout=10ms"})
+	if err == nil || err.Error() != "2 of 3 checks failed" {
+		t.Errorf("Doctor() error = %v, want 2 of 3 checks failed", err)
+	}
+	want := "ok    proxy: none set\n" +
+		"FAIL  reach google: boom\n" +
+		"FAIL  create a session: context deadline exceeded\n"
+	if buf.String() != want {
+		t.Errorf("Doctor() printed:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
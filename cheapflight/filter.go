@@ -0,0 +1,104 @@
+package cheapflight
+
+import (
+	"strings"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// filterOffers drops the offers rejected by any of the configured filters or
+// flown by excludedAirline. options are those of the search that found the
+// offers.
+func (c Config) filterOffers(offers []flights.FullOffer, options flights.Options, excludedAirline string) []flights.FullOffer {
+	var kept []flights.FullOffer
+	for _, o := range offers {
+		if excludedAirline != "" && fliesAirline(o, excludedAirline) {
+			continue
+		}
+		if c.MaxTotalCost > 0 && c.totalCost(o, options) > c.MaxTotalCost {
+			continue
+		}
+		if c.MinConnectionTime > 0 && shortestConnection(o) < c.MinConnectionTime {
+			continue
+		}
+		if len(c.ExcludedFlightNumbers) > 0 && containsFlightNumber(o, c.ExcludedFlightNumbers) {
+			continue
+		}
+		if c.Alliance != "" && !inAlliance(o, c.Alliance) {
+			continue
+		}
+		kept = append(kept, o)
+	}
+	return kept
+}
+
+// totalCost is what o costs the whole party: its price, which covers every
+// traveler, plus --bag-fee for each traveler and direction flown.
+func (c Config) totalCost(o flights.FullOffer, options flights.Options) float64 {
+	directions := 1
+	if options.TripType == flights.RoundTrip {
+		directions = 2
+	}
+	return o.Price + c.BagFee*float64(totalTravelers(options.Travelers)*directions)
+}
+
+// excludesTrip reports whether a trip departing on departure and returning on
+// ret touches any --exclude-dates date, falls outside --depart-days or
+// --return-days, or departs sooner than --min-advance-days. A zero ret is a
+// one-way trip.
+func (c Config) excludesTrip(departure, ret time.Time) bool {
+	if c.ExcludedDates[dateKey(departure)] {
+		return true
+	}
+	if c.MinAdvanceDays > 0 && dateKey(departure) < dateKey(c.now().AddDate(0, 0, c.MinAdvanceDays)) {
+		return true
+	}
+	if len(c.DepartDays) > 0 && !c.DepartDays[departure.Weekday()] {
+		return true
+	}
+	if ret.IsZero() {
+		return false
+	}
+	return c.ExcludedDates[dateKey(ret)] || len(c.ReturnDays) > 0 && !c.ReturnDays[ret.Weekday()]
+}
+
+// shortestConnection returns the shortest gap between landing and the next
+// departure, or the largest duration for nonstop offers.
+func shortestConnection(o flights.FullOffer) time.Duration {
+	shortest := time.Duration(1<<63 - 1)
+	for i := 1; i < len(o.Flight); i++ {
+		if gap := o.Flight[i].DepTime.Sub(o.Flight[i-1].ArrTime); gap < shortest {
+			shortest = gap
+		}
+	}
+	return shortest
+}
+
+// normalizeFlightNumber turns "UA 123" and "ua123" into "UA123".
+func normalizeFlightNumber(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
+
+// fliesAirline reports whether any flight of o is operated by an airline named
+// in airlines.
+func fliesAirline(o flights.FullOffer, airlines string) bool {
+	for _, f := range o.Flight {
+		if strings.Contains(airlines, f.AirlineName) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFlightNumber(o flights.FullOffer, numbers []string) bool {
+	for _, f := range o.Flight {
+		flightNumber := normalizeFlightNumber(f.FlightNumber)
+		for _, n := range numbers {
+			if flightNumber == n {
+				return true
+			}
+		}
+	}
+	return false
+}
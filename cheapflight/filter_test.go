@@ -0,0 +1,166 @@
+package cheapflight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// connecting returns an offer landing at 10:00 and connecting after each of gaps.
+func connecting(price float64, gaps ...time.Duration) flights.FullOffer {
+	o := offerOn(day1, price)
+	arr := day1.Add(10 * time.Hour)
+	o.Flight = []flights.Flight{{DepTime: arr.Add(-2 * time.Hour), ArrTime: arr}}
+	for _, gap := range gaps {
+		dep := arr.Add(gap)
+		arr = dep.Add(2 * time.Hour)
+		o.Flight = append(o.Flight, flights.Flight{DepTime: dep, ArrTime: arr})
+	}
+	return o
+}
+
+func TestMinConnectionTime(t *testing.T) {
+	tests := []struct {
+		name string
+		min  time.Duration
+		gaps []time.Duration
+		want bool
+	}{
+		{name: "25m dropped under 45m", min: 45 * time.Minute, gaps: []time.Duration{25 * time.Minute}, want: false},
+		{name: "exactly the minimum", min: 45 * time.Minute, gaps: []time.Duration{45 * time.Minute}, want: true},
+		{name: "one short connection of two", min: 45 * time.Minute, gaps: []time.Duration{2 * time.Hour, 30 * time.Minute}, want: false},
+		{name: "nonstop", min: 45 * time.Minute, want: true},
+		{name: "no minimum", gaps: []time.Duration{10 * time.Minute}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{MinConnectionTime: tt.min}
+			kept := cfg.filterOffers([]flights.FullOffer{connecting(100, tt.gaps...)}, roundTrip(), "")
+			if got := len(kept) == 1; got != tt.want {
+				t.Errorf("offer kept = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withFlights returns an offer made of flights with the given flight numbers.
+func withFlights(numbers ...string) flights.FullOffer {
+	o := offerOn(day1, 100)
+	for _, n := range numbers {
+		o.Flight = append(o.Flight, flights.Flight{FlightNumber: n})
+	}
+	return o
+}
+
+func TestExcludeFlightNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		excluded []string
+		offer    flights.FullOffer
+		want     bool
+	}{
+		{name: "excluded first segment", excluded: []string{"UA123"}, offer: withFlights("UA 123", "LH 400"), want: false},
+		{name: "excluded connecting segment", excluded: []string{"UA123", "AA456"}, offer: withFlights("LH 401", "aa456"), want: false},
+		{name: "no excluded segment", excluded: []string{"UA123"}, offer: withFlights("UA 1234", "LH 400"), want: true},
+		{name: "nothing excluded", offer: withFlights("UA 123"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--exclude-flight-numbers=" + strings.Join(tt.excluded, ",")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			kept := cfg.filterOffers([]flights.FullOffer{tt.offer}, roundTrip(), "")
+			if got := len(kept) == 1; got != tt.want {
+				t.Errorf("offer kept = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlliance(t *testing.T) {
+	offers := []flights.FullOffer{
+		withFlights("UA 1"),
+		withFlights("LH 400", "ua 2"),
+		withFlights("AA 100"),
+		withFlights("DL 5"),
+		withFlights("UA 3", "AA 4"),
+		withFlights("WN 7"),
+		withFlights(),
+	}
+	tests := []struct {
+		alliance string
+		want     []string
+	}{
+		{alliance: "star", want: []string{"UA 1", "LH 400"}},
+		{alliance: "Oneworld", want: []string{"AA 100"}},
+		{alliance: "skyteam", want: []string{"DL 5"}},
+		{alliance: "", want: []string{"UA 1", "LH 400", "AA 100", "DL 5", "UA 3", "WN 7", ""}},
+	}
+	for _, tt := range tests {
+		cfg, err := ProcessFlags([]string{"--alliance=" + tt.alliance})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, o := range cfg.filterOffers(offers, roundTrip(), "") {
+			first := ""
+			if len(o.Flight) > 0 {
+				first = o.Flight[0].FlightNumber
+			}
+			got = append(got, first)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("--alliance=%s kept %q, want %q", tt.alliance, got, tt.want)
+		}
+	}
+
+	if _, err := ProcessFlags([]string{"--alliance=vanilla"}); err == nil {
+		t.Error("accepted an unknown alliance")
+	}
+}
+
+func TestMaxTotalCost(t *testing.T) {
+	// Prices already cover all three travelers; bag fees are added per traveler
+	// and direction flown.
+	threeAdults := roundTrip()
+	threeAdults.Travelers = flights.Travelers{Adults: 3}
+	oneWay := threeAdults
+	oneWay.TripType = flights.OneWay
+
+	tests := []struct {
+		name     string
+		flags    []string
+		options  flights.Options
+		prices   []float64
+		wantKept []float64
+	}{
+		{name: "no bag fee", flags: []string{"--max-total-cost=1500"}, options: threeAdults, prices: []float64{1400, 1500, 1501}, wantKept: []float64{1400, 1500}},
+		{name: "round trip bag fees", flags: []string{"--max-total-cost=1500", "--bag-fee=30"}, options: threeAdults, prices: []float64{1300, 1320, 1321}, wantKept: []float64{1300, 1320}},
+		{name: "one way bag fees", flags: []string{"--max-total-cost=1500", "--bag-fee=30"}, options: oneWay, prices: []float64{1320, 1410, 1411}, wantKept: []float64{1320, 1410}},
+		{name: "no budget", flags: []string{"--bag-fee=30"}, options: threeAdults, prices: []float64{5000}, wantKept: []float64{5000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags(tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var offers []flights.FullOffer
+			for _, price := range tt.prices {
+				offers = append(offers, offerOn(day1, price))
+			}
+
+			var kept []float64
+			for _, o := range cfg.filterOffers(offers, tt.options, "") {
+				kept = append(kept, o.Price)
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
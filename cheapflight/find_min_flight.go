@@ -1,11 +1,10 @@
 package cheapflight
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"math"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,8 +14,13 @@ import (
 	"golang.org/x/text/language"
 )
 
+// dateLayouts are the date argument layouts tried in order when no
+// --date-input-format is given: mm-dd-yyyy, then ISO 8601.
+var dateLayouts = []string{defaultDateFormat, time.DateOnly}
+
 const (
 	defaultDateFormat   = "01-02-2006"
+	defaultSeparator    = ","
 	startDateArg        = 0
 	endDateArg          = 1
 	durationArg         = 2
@@ -28,55 +32,193 @@ const (
 	stopArg             = 8
 	excludedAirlinesArg = 9
 	targetArg           = 10
+	smsNumberArg        = 11
+	numArgs             = 13
+
+	// fixedDates as the trip length searches only the exact start and end dates.
+	fixedDates = -1
+
+	// maxTravelers is the most passengers Google Flights accepts in one search.
+	maxTravelers = 9
+
+	maxConcurrentRequests = 4
+
+	// maxDatePairs bounds the departure and return date pairs searched with
+	// separate return date ranges, each of which is one offers request.
+	maxDatePairs = 60
 )
 
 //Todo target price in fixed date and range
 
 type Message struct {
-	Price int
-	Url   string
-	Start string
-	End   string
+	Price    int    `json:"price"`
+	Url      string `json:"url,omitempty"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Src      string `json:"src"`
+	Dst      string `json:"dst"`
+	Airline  string `json:"airline,omitempty"`
+	Currency string `json:"currency,omitempty"`
+
+	PriceRange *flights.PriceRange `json:"price_range,omitempty"`
+	Deal       bool                `json:"deal"`
+	// DealUnknown is set when there was no price range or history to judge Deal by.
+	DealUnknown bool `json:"deal_unknown,omitempty"`
+	// Travelers and PerPersonPrice split Price evenly across the party with --budget-split.
+	Travelers      int     `json:"travelers,omitempty"`
+	PerPersonPrice float64 `json:"per_person_price,omitempty"`
+	// Prices holds Price converted into each --display-currencies currency.
+	Prices map[string]float64 `json:"prices,omitempty"`
+	// PricePerMile is Price divided by the great circle miles flown, when both
+	// airports have known coordinates.
+	PricePerMile float64 `json:"price_per_mile,omitempty"`
+	// QuotedPrice is the searched price when --confirm-price found a different live price.
+	QuotedPrice int       `json:"quoted_price,omitempty"`
+	Segments    []Segment `json:"segments,omitempty"`
+	// Legs holds the outbound and return one-way offers of a --split-legs result.
+	Legs []Message `json:"legs,omitempty"`
+	// Direction is "outbound" or "return" with --reverse.
+	Direction string `json:"direction,omitempty"`
+	// Class is the cabin class the offer was found in with --class-fallback, and
+	// RequestedClass the class asked for when a fallback class was used.
+	Class          string `json:"class,omitempty"`
+	RequestedClass string `json:"requested_class,omitempty"`
+	// Partial is set when --limit-api-calls was reached during the search, so
+	// some dates went unsearched or the booking link is missing.
+	Partial bool `json:"partial,omitempty"`
+
+	departure  time.Time
+	arrival    time.Time
+	returnDate time.Time
+	// route is the searched route the message answers, empty outside route searches.
+	route string
+	// notify is the alert channels of the route, empty for the --notify channels.
+	notify []string
+	// candidates are the offers of the search, kept with --min-deal-count.
+	candidates []dealCandidate
 }
 
-func ProcessArgs() (flights.PriceGraphArgs, string, float64, string, error) {
-	if len(os.Args) != 13 {
-		return flights.PriceGraphArgs{}, "", -1, "", errors.New("missing minimum number of args")
+// Segment is one flight of an offer, included in results with --include-segments.
+type Segment struct {
+	Airline         string    `json:"airline"`
+	FlightNumber    string    `json:"flight_number"`
+	DepAirport      string    `json:"dep_airport"`
+	ArrAirport      string    `json:"arr_airport"`
+	DepTime         time.Time `json:"dep_time"`
+	ArrTime         time.Time `json:"arr_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+func newSegments(o flights.FullOffer) []Segment {
+	var segments []Segment
+	for _, f := range o.Flight {
+		segments = append(segments, Segment{
+			Airline:         f.AirlineName,
+			FlightNumber:    f.FlightNumber,
+			DepAirport:      f.DepAirportCode,
+			ArrAirport:      f.ArrAirportCode,
+			DepTime:         f.DepTime,
+			ArrTime:         f.ArrTime,
+			DurationMinutes: int(f.Duration.Minutes()),
+		})
 	}
+	return segments
+}
 
-	args := os.Args[1:]
-	startDate, err := time.Parse(defaultDateFormat, args[startDateArg])
-	endDate, err := time.Parse(defaultDateFormat, args[endDateArg])
+func newMessage(o flights.FullOffer, url string) Message {
+	return Message{
+		Price:      int(o.Price),
+		Url:        url,
+		Start:      formatLocalTime(o.StartDate, o.SrcAirportCode),
+		End:        o.ReturnDate.Format(time.DateOnly),
+		Src:        o.SrcAirportCode,
+		Dst:        o.DstAirportCode,
+		Airline:    offerAirlines(o),
+		departure:  o.StartDate,
+		arrival:    offerArrival(o),
+		returnDate: o.ReturnDate,
+	}
+}
 
-	if err != nil {
-		return flights.PriceGraphArgs{}, "", -1, "", err
+func (m Message) currency() string {
+	if m.Currency == "" {
+		return currency.USD.String()
 	}
+	return m.Currency
+}
 
-	duration, _ := strconv.Atoi(args[durationArg])
-	start := strings.Split(args[startArg], "-")
-	end := strings.Split(args[endArg], "-")
+// dealStatus is "true" or "false" for Deal, or "unknown" when it could not be judged.
+func (m Message) dealStatus() string {
+	if m.DealUnknown {
+		return "unknown"
+	}
+	return strconv.FormatBool(m.Deal)
+}
 
-	if len(start) == 0 || len(end) == 0 {
-		return flights.PriceGraphArgs{}, "", -1, "", errors.New("need a start and destination city")
+// splitBudget records the fare per traveler of m, whose Price covers all travelers.
+func (m *Message) splitBudget(travelers int) {
+	if travelers <= 0 {
+		return
 	}
+	m.Travelers = travelers
+	m.PerPersonPrice = math.Round(float64(m.Price)/float64(travelers)*100) / 100
+}
 
-	var airportsSrc, citiesSrc []string
-	for _, possibleCity := range start {
-		if strings.ToUpper(possibleCity) == possibleCity && len(possibleCity) == 3 {
-			airportsSrc = append(airportsSrc, possibleCity)
-		} else {
-			citiesSrc = append(citiesSrc, possibleCity)
+func offerArrival(o flights.FullOffer) time.Time {
+	if len(o.Flight) == 0 {
+		return o.StartDate.Add(o.FlightDuration)
+	}
+	return o.Flight[len(o.Flight)-1].ArrTime
+}
+
+func offerAirlines(o flights.FullOffer) string {
+	var airlines []string
+	for _, f := range o.Flight {
+		if len(airlines) == 0 || airlines[len(airlines)-1] != f.AirlineName {
+			airlines = append(airlines, f.AirlineName)
 		}
 	}
+	return strings.Join(airlines, "/")
+}
 
-	var airportsDst, citiesDst []string
-	for _, possibleCity := range end {
-		if strings.ToUpper(possibleCity) == possibleCity && len(possibleCity) == 3 {
-			airportsDst = append(airportsDst, possibleCity)
-		} else {
-			citiesDst = append(citiesDst, possibleCity)
+func ProcessArgs(cfg Config) (flights.PriceGraphArgs, string, float64, string, error) {
+	if len(cfg.requestArgs()) < numArgs {
+		return flights.PriceGraphArgs{}, "", -1, "", errors.New("missing minimum number of args")
+	}
+
+	args := cfg.requestArgs()[1:]
+	var err error
+	var startDate, endDate time.Time
+	// duration is the number of nights between departure and return, or
+	// fixedDates to fly out on the start date and return on the end date.
+	var duration int
+	if cfg.Preset != "" {
+		// A preset replaces the date and trip length arguments.
+		startDate, endDate, err = presetDates(cfg.Preset, cfg.now())
+		if err != nil {
+			return flights.PriceGraphArgs{}, "", -1, "", err
+		}
+		duration = fixedDates
+	} else {
+		startDate, err = parseDate(args[startDateArg], cfg.DateInputFormat)
+		if err != nil {
+			return flights.PriceGraphArgs{}, "", -1, "", err
+		}
+		endDate, err = parseDate(args[endDateArg], cfg.DateInputFormat)
+		if err != nil {
+			return flights.PriceGraphArgs{}, "", -1, "", err
+		}
+		duration, err = strconv.Atoi(args[durationArg])
+		if err != nil {
+			return flights.PriceGraphArgs{}, "", -1, "", errors.New("need a trip length in nights, or -1 for fixed dates")
 		}
 	}
+	airportsSrc, citiesSrc := parseLocations(args[startArg], cfg.Separator)
+	airportsDst, citiesDst := parseLocations(args[endArg], cfg.Separator)
+
+	if len(airportsSrc)+len(citiesSrc) == 0 || len(airportsDst)+len(citiesDst) == 0 {
+		return flights.PriceGraphArgs{}, "", -1, "", errors.New("need a start and destination city")
+	}
 
 	options := flights.Options{
 		Travelers: flights.Travelers{Adults: 1},
@@ -89,7 +231,7 @@ func ProcessArgs() (flights.PriceGraphArgs, string, float64, string, error) {
 
 	if args[travelerArg] != "default" {
 		passengerNum, err := strconv.Atoi(args[travelerArg])
-		if err != nil {
+		if err == nil {
 			options.Travelers = flights.Travelers{Adults: passengerNum}
 		}
 	}
@@ -114,6 +256,10 @@ func ProcessArgs() (flights.PriceGraphArgs, string, float64, string, error) {
 		options.TripType = flights.OneWay
 	}
 
+	if duration != fixedDates && duration <= 0 && options.TripType == flights.RoundTrip {
+		return flights.PriceGraphArgs{}, "", -1, "", fmt.Errorf("round trips need a positive trip length in nights, got %d", duration)
+	}
+
 	if args[stopArg] != "default" {
 		stops, err := strconv.ParseInt(args[stopArg], 10, 64)
 		if err == nil {
@@ -153,164 +299,204 @@ func ProcessArgs() (flights.PriceGraphArgs, string, float64, string, error) {
 		DstAirports:    airportsDst,
 		Options:        options,
 	}
+	if err := validateLocations(cheapestArgs); err != nil {
+		return flights.PriceGraphArgs{}, "", -1, "", err
+	}
 
-	SMSNumber := os.Args[11]
+	SMSNumber := args[smsNumberArg]
 	return cheapestArgs, excludedAirlines, target, SMSNumber, nil
 }
 
-func GetCheapestOffersRange(args flights.PriceGraphArgs, excludedAirline string) Message {
-	options := args.Options
-
-	session, err := flights.New()
-	if err != nil {
-		fmt.Println(err.Error())
-		return Message{}
+// parseDate parses a date argument with layout, or with each of dateLayouts in
+// turn when layout is empty.
+func parseDate(arg, layout string) (time.Time, error) {
+	layouts := dateLayouts
+	if layout != "" {
+		layouts = []string{layout}
 	}
-
-	priceGraphOffers, err := session.GetPriceGraph(
-		context.Background(),
-		args,
-	)
-
-	if err != nil {
-		fmt.Println(err.Error())
-		return Message{}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, arg); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected one of %s", arg, strings.Join(layouts, ", "))
+}
 
-	var bestOffer flights.FullOffer
-	for _, priceGraphOffer := range priceGraphOffers {
-		offers, _, err := session.GetOffers(
-			context.Background(),
-			flights.Args{
-				Date:        priceGraphOffer.StartDate,
-				ReturnDate:  priceGraphOffer.ReturnDate,
-				SrcCities:   args.SrcCities,
-				DstCities:   args.DstCities,
-				SrcAirports: args.SrcAirports,
-				DstAirports: args.DstAirports,
-				Options:     options,
-			},
-		)
-
+// parseDateList parses a comma separated list of dates and start..end ranges,
+// both ends included, into a set of dateKeys.
+func parseDateList(arg, layout string) (map[string]bool, error) {
+	dates := make(map[string]bool)
+	for _, item := range splitList(arg, ",") {
+		first, last, isRange := strings.Cut(item, "..")
+		start, err := parseDate(strings.TrimSpace(first), layout)
 		if err != nil {
-			fmt.Println(err.Error())
-			return Message{}
+			return nil, err
 		}
-		for _, o := range offers {
-			if o.Price != 0 && (bestOffer.Price == 0 || o.Price < bestOffer.Price) {
-				if len(excludedAirline) > 0 {
-					containsExcluded := false
-					for _, f := range o.Flight {
-						if strings.Contains(excludedAirline, f.AirlineName) {
-							containsExcluded = true
-							break
-						}
-					}
-					if !containsExcluded {
-						bestOffer = o
-					}
-				} else {
-					bestOffer = o
+		end := start
+		if isRange {
+			end, err = parseDate(strings.TrimSpace(last), layout)
+			if err != nil {
+				return nil, err
+			}
+			if end.Before(start) {
+				return nil, fmt.Errorf("range %q ends before it starts", item)
+			}
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			dates[dateKey(d)] = true
+		}
+	}
+	return dates, nil
+}
+
+// parseWeekdays parses a comma separated list of weekday names such as
+// "fri,sat" or "Friday" into a set. An empty list allows every day.
+func parseWeekdays(arg string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, name := range splitList(arg, ",") {
+		day, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			for d := time.Sunday; d <= time.Saturday; d++ {
+				if strings.EqualFold(name, d.String()) {
+					day, ok = d, true
 				}
 			}
 		}
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+		days[day] = true
 	}
+	return days, nil
+}
 
-	if bestOffer.Price != 0 {
-		url, err := session.SerializeURL(
-			context.Background(),
-			flights.Args{
-				Date:        bestOffer.StartDate,
-				ReturnDate:  bestOffer.ReturnDate,
-				SrcAirports: []string{bestOffer.SrcAirportCode},
-				DstAirports: []string{bestOffer.DstAirportCode},
-				Options:     options,
-			},
-		)
-		if err != nil {
-			fmt.Println(err.Error())
-			return Message{}
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// tripReturnDate is the return date of a trip departing on start and staying nights nights.
+func tripReturnDate(start time.Time, nights int) time.Time {
+	return start.AddDate(0, 0, nights)
+}
+
+func totalTravelers(t flights.Travelers) int {
+	return t.Adults + t.Children + t.InfantInSeat + t.InfantOnLap
+}
+
+func validateTravelers(t flights.Travelers) error {
+	if t.Adults < 0 || t.Children < 0 || t.InfantInSeat < 0 || t.InfantOnLap < 0 {
+		return errors.New("traveler counts must not be negative")
+	}
+	total := totalTravelers(t)
+	if total == 0 {
+		return errors.New("need at least one traveler")
+	}
+	if total > maxTravelers {
+		return fmt.Errorf("%d travelers requested, at most %d are supported", total, maxTravelers)
+	}
+	if t.InfantOnLap > t.Adults {
+		return fmt.Errorf("%d infants on lap need at least as many adults, got %d", t.InfantOnLap, t.Adults)
+	}
+	return nil
+}
+
+// validateLocations rejects searches where a source is also a destination.
+func validateLocations(args flights.PriceGraphArgs) error {
+	for _, src := range concat(args.SrcAirports, args.SrcCities) {
+		for _, dst := range concat(args.DstAirports, args.DstCities) {
+			if strings.EqualFold(src, dst) {
+				return fmt.Errorf("%s is both a source and a destination", src)
+			}
 		}
-		return Message{
-			Price: int(bestOffer.Price),
-			Url:   url,
-			Start: bestOffer.StartDate.String(),
-			End:   bestOffer.ReturnDate.String(),
+	}
+	return nil
+}
+
+// concat returns a followed by b in a new slice. Unlike append(a, b...) it
+// never writes into the backing array of a.
+func concat(a, b []string) []string {
+	all := make([]string, 0, len(a)+len(b))
+	all = append(all, a...)
+	return append(all, b...)
+}
+
+// splitList splits a list such as airports and cities on sep, leaving hyphenated
+// city names such as winston-salem intact with the default comma separator.
+func splitList(arg, sep string) []string {
+	if sep == "" {
+		sep = defaultSeparator
+	}
+
+	var locations []string
+	for _, l := range strings.Split(arg, sep) {
+		if l = strings.TrimSpace(l); l != "" {
+			locations = append(locations, l)
+		}
+	}
+	return locations
+}
+
+// parseLocations splits arg into IATA airport codes and city names.
+func parseLocations(arg, sep string) (airports, cities []string) {
+	for _, possibleCity := range splitList(arg, sep) {
+		if strings.ToUpper(possibleCity) == possibleCity && len(possibleCity) == 3 {
+			airports = append(airports, possibleCity)
+		} else {
+			cities = append(cities, possibleCity)
 		}
-	} else {
-		fmt.Println(fmt.Errorf("failed to find a flight in that range"))
-		return Message{}
 	}
+	return airports, cities
+}
+
+func GetCheapestOffersRange(args flights.PriceGraphArgs, excludedAirline string, cfg Config) Message {
+	messages, err := searchOffers(args, excludedAirline, cfg, findOffersRange)
+	return firstMessage(messages, err, cfg)
 }
 
-func GetCheapestOffersFixedDates(args flights.PriceGraphArgs, excludedAirline string) Message {
-	session, err := flights.New()
+func GetCheapestOffersFixedDates(args flights.PriceGraphArgs, excludedAirline string, cfg Config) Message {
+	messages, err := searchOffers(args, excludedAirline, cfg, findOffersFixedDates)
+	return firstMessage(messages, err, cfg)
+}
+
+func firstMessage(messages []Message, err error, cfg Config) Message {
 	if err != nil {
-		fmt.Println(err.Error())
+		cfg.logger().Error(err.Error())
 		return Message{}
 	}
+	return messages[0]
+}
 
-	offers, _, err := session.GetOffers(
-		context.Background(),
-		flights.Args{
-			Date:        args.RangeStartDate,
-			ReturnDate:  args.RangeEndDate,
-			SrcCities:   args.SrcCities,
-			DstCities:   args.DstCities,
-			SrcAirports: args.SrcAirports,
-			DstAirports: args.DstAirports,
-			Options:     args.Options,
-		},
-	)
-	if err != nil || len(offers) == 0 {
-		fmt.Println(fmt.Errorf("unable to obtain offers for this flight request"))
-		return Message{}
+// limitOffers keeps only the max best offers according to less, unpriced offers
+// sorting last. A max of 0 or less keeps every offer.
+func limitOffers(offers []flights.FullOffer, max int, less offerLess) []flights.FullOffer {
+	if max <= 0 || len(offers) <= max {
+		return offers
 	}
 
+	sorted := append([]flights.FullOffer(nil), offers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Price == 0 {
+			return false
+		}
+		return sorted[j].Price == 0 || less(sorted[i], sorted[j])
+	})
+	return sorted[:max]
+}
+
+func selectBestOffer(offers []flights.FullOffer, excludedAirline string, less offerLess) flights.FullOffer {
 	var bestOffer flights.FullOffer
 	for _, o := range offers {
-		if o.Price != 0 && (bestOffer.Price == 0 || o.Price < bestOffer.Price) {
-			if len(excludedAirline) > 0 {
-				containsExcluded := false
-				for _, f := range o.Flight {
-					if strings.Contains(excludedAirline, f.AirlineName) {
-						containsExcluded = true
-						break
-					}
-				}
-				if !containsExcluded {
-					bestOffer = o
-				}
-			} else {
+		if o.Price != 0 && (bestOffer.Price == 0 || less(o, bestOffer)) {
+			if len(excludedAirline) == 0 || !fliesAirline(o, excludedAirline) {
 				bestOffer = o
 			}
 		}
 	}
-
-	if bestOffer.Price == 0 {
-		fmt.Println(fmt.Errorf("failed to find a flight that does not contain an excluded airline"))
-		return Message{}
-	} else {
-		url, err := session.SerializeURL(
-			context.Background(),
-			flights.Args{
-				Date:        bestOffer.StartDate,
-				ReturnDate:  bestOffer.ReturnDate,
-				SrcAirports: []string{bestOffer.SrcAirportCode},
-				DstAirports: []string{bestOffer.DstAirportCode},
-				Options:     args.Options,
-			},
-		)
-		if err != nil {
-			fmt.Println(err.Error())
-			return Message{}
-		}
-		return Message{
-			Price: int(bestOffer.Price),
-			Url:   url,
-			Start: bestOffer.StartDate.String(),
-			End:   bestOffer.ReturnDate.String(),
-		}
-	}
-
+	return bestOffer
 }
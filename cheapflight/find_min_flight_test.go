@@ -0,0 +1,232 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func prices(offers []flights.FullOffer) []float64 {
+	var p []float64
+	for _, o := range offers {
+		p = append(p, o.Price)
+	}
+	return p
+}
+
+func offersPriced(p ...float64) []flights.FullOffer {
+	var offers []flights.FullOffer
+	for _, price := range p {
+		offers = append(offers, offerOn(day1, price))
+	}
+	return offers
+}
+
+func TestLimitOffers(t *testing.T) {
+	tests := []struct {
+		name   string
+		offers []flights.FullOffer
+		max    int
+		want   []float64
+	}{
+		{"cheapest K", offersPriced(300, 100, 250, 150), 2, []float64{100, 150}},
+		{"unpriced last", offersPriced(0, 200, 0, 180), 3, []float64{180, 200, 0}},
+		{"zero keeps all", offersPriced(300, 100), 0, []float64{300, 100}},
+		{"fewer than max", offersPriced(300), 5, []float64{300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := prices(limitOffers(tt.offers, tt.max, Config{}.lessOffer))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("limitOffers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLocations(t *testing.T) {
+	tests := []struct {
+		arg          string
+		sep          string
+		wantAirports []string
+		wantCities   []string
+	}{
+		{arg: "winston-salem,charlotte", wantCities: []string{"winston-salem", "charlotte"}},
+		{arg: "SFO, OAK,san jose", wantAirports: []string{"SFO", "OAK"}, wantCities: []string{"san jose"}},
+		{arg: "winston-salem", wantCities: []string{"winston-salem"}},
+		{arg: "SFO-OAK", sep: "-", wantAirports: []string{"SFO", "OAK"}},
+		{arg: "SFO;winston-salem", sep: ";", wantAirports: []string{"SFO"}, wantCities: []string{"winston-salem"}},
+		{arg: "sfo,,", wantCities: []string{"sfo"}},
+	}
+	for _, tt := range tests {
+		airports, cities := parseLocations(tt.arg, tt.sep)
+		if !reflect.DeepEqual(airports, tt.wantAirports) || !reflect.DeepEqual(cities, tt.wantCities) {
+			t.Errorf("parseLocations(%q, %q) = %q, %q, want %q, %q", tt.arg, tt.sep, airports, cities, tt.wantAirports, tt.wantCities)
+		}
+	}
+}
+
+func TestValidateTravelers(t *testing.T) {
+	tests := []struct {
+		name      string
+		travelers flights.Travelers
+		wantErr   bool
+	}{
+		{name: "one adult", travelers: flights.Travelers{Adults: 1}},
+		{name: "at the limit", travelers: flights.Travelers{Adults: 4, Children: 3, InfantOnLap: 2}},
+		{name: "over the limit", travelers: flights.Travelers{Adults: 6, Children: 4}, wantErr: true},
+		{name: "more lap infants than adults", travelers: flights.Travelers{Adults: 1, InfantOnLap: 2}, wantErr: true},
+		{name: "seated infants need no adult lap", travelers: flights.Travelers{Adults: 1, InfantInSeat: 2}},
+		{name: "nobody", travelers: flights.Travelers{}, wantErr: true},
+		{name: "negative", travelers: flights.Travelers{Adults: 2, Children: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateTravelers(tt.travelers); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateTravelers(%+v) error = %v, want error %v", tt.name, tt.travelers, err, tt.wantErr)
+		}
+	}
+}
+
+// withArgs sets os.Args to a request for the positional arguments for the
+// rest of the test.
+func withArgs(t *testing.T, positional ...string) {
+	t.Helper()
+	saved := os.Args
+	t.Cleanup(func() { os.Args = saved })
+	os.Args = append([]string{"runway"}, positional...)
+}
+
+func TestTripLengthNights(t *testing.T) {
+	tests := []struct {
+		name     string
+		nights   string
+		tripType string
+		want     time.Time
+		wantErr  bool
+	}{
+		{name: "three nights", nights: "3", tripType: "RoundTrip", want: day4},
+		{name: "one night", nights: "1", tripType: "RoundTrip", want: day2},
+		{name: "zero nights round trip", nights: "0", tripType: "RoundTrip", wantErr: true},
+		{name: "negative nights", nights: "-2", tripType: "RoundTrip", wantErr: true},
+		{name: "not a number", nights: "3d", tripType: "RoundTrip", wantErr: true},
+		{name: "one way ignores the length", nights: "0", tripType: "OneWay", want: day1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withArgs(t, "2030-03-01", "2030-03-01", tt.nights, "SFO", "JFK", "default", "default", tt.tripType, "default", "default", "default", "", "")
+			args, _, _, _, err := ProcessArgs(Config{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessArgs() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			api := &stubAPI{priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+				return []flights.Offer{{StartDate: day1, Price: 100}}, nil
+			}}
+			cfg := withStub(Config{}, api)
+			if _, err := findOffersRange(context.Background(), cfg.session(), args, "", cfg); err != nil {
+				t.Fatal(err)
+			}
+			if len(api.offerCalls) != 1 {
+				t.Fatalf("got %d offers calls, want 1", len(api.offerCalls))
+			}
+			if got := api.offerCalls[0].ReturnDate; !got.Equal(tt.want) {
+				t.Errorf("return date = %s, want %s", got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2030, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		arg     string
+		format  string
+		wantErr bool
+	}{
+		{arg: "03-14-2030"},
+		{arg: "2030-03-14"},
+		{arg: "14/03/2030", format: "dd/mm/yyyy"},
+		{arg: "2030-03-14", format: "yyyy-mm-dd"},
+		{arg: "03-14-2030", format: "mm-dd-yyyy"},
+		{arg: "14/03/2030", wantErr: true},
+		{arg: "03-14-2030", format: "yyyy-mm-dd", wantErr: true},
+		{arg: "2030-14-03", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDate(tt.arg, dateInputFormats[tt.format])
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDate(%q, %q) error = %v, want error %v", tt.arg, tt.format, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(want) {
+			t.Errorf("parseDate(%q, %q) = %s, want %s", tt.arg, tt.format, got, want)
+		}
+	}
+}
+
+func TestValidateLocations(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    flights.PriceGraphArgs
+		wantErr bool
+	}{
+		{name: "same airport", args: flights.PriceGraphArgs{SrcAirports: []string{"SFO"}, DstAirports: []string{"SFO"}}, wantErr: true},
+		{name: "different airports", args: flights.PriceGraphArgs{SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}}},
+		{name: "shared among several", args: flights.PriceGraphArgs{SrcAirports: []string{"SFO", "OAK"}, DstAirports: []string{"JFK", "oak"}}, wantErr: true},
+		{name: "same city", args: flights.PriceGraphArgs{SrcCities: []string{"Paris"}, DstAirports: []string{"JFK"}, DstCities: []string{"paris"}}, wantErr: true},
+		{name: "different cities", args: flights.PriceGraphArgs{SrcCities: []string{"Paris"}, DstCities: []string{"London"}}},
+	}
+	for _, tt := range tests {
+		if err := validateLocations(tt.args); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConcatLeavesArgumentsAlone(t *testing.T) {
+	// Spare capacity after the airports is where append would write the cities.
+	backing := []string{"SFO", "spare"}
+	args := flights.PriceGraphArgs{SrcAirports: backing[:1], SrcCities: []string{"Paris"}, DstAirports: []string{"JFK"}}
+	if err := validateLocations(args); err != nil {
+		t.Fatal(err)
+	}
+	if got := concat(args.SrcAirports, args.SrcCities); len(got) != 2 || got[1] != "Paris" {
+		t.Errorf("concat = %q, want [SFO Paris]", got)
+	}
+	if backing[1] != "spare" {
+		t.Errorf("caller's backing array was overwritten with %q", backing[1])
+	}
+}
+
+func TestCheapestOfferLogsErrors(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		return nil, nil, errBoom
+	}}
+	var logged bytes.Buffer
+	cfg := withStub(Config{}, api)
+	cfg.log = slog.New(slog.NewTextHandler(&logged, nil))
+
+	output := captureStd(t)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, Options: roundTrip()}
+	if got := GetCheapestOffersFixedDates(args, "", cfg); got.Price != 0 {
+		t.Errorf("GetCheapestOffersFixedDates() = %+v, want no offer", got)
+	}
+
+	// Stdout only carries results, so that --format json stays parseable.
+	if out := output(); out != "" {
+		t.Errorf("printed %q, want nothing", out)
+	}
+	if !strings.Contains(logged.String(), "unable to obtain offers") {
+		t.Errorf("logged %q, want the search error", logged.String())
+	}
+}
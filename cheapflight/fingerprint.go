@@ -0,0 +1,125 @@
+package cheapflight
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// alertFileMu serializes access to alert files shared by concurrent requests.
+var alertFileMu sync.Mutex
+
+// fingerprint identifies an offer by route, dates, price and airline so the
+// same deal is only alerted on once.
+func fingerprint(m Message) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d|%s", m.Src, m.Dst, m.Start, m.End, m.Price, m.Airline)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// alertStore is the set of fingerprints already alerted on, persisted as JSON
+// mapping each fingerprint to the departure date after which it expires.
+type alertStore struct {
+	path string
+	seen map[string]time.Time
+}
+
+func loadAlertStore(path string) (*alertStore, error) {
+	alertFileMu.Lock()
+	defer alertFileMu.Unlock()
+
+	s := &alertStore{path: path, seen: make(map[string]time.Time)}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reload merges the fingerprints saved to the file into the store, so that
+// those recorded by other requests sharing the file are kept. The caller holds
+// alertFileMu.
+func (s *alertStore) reload() error {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved map[string]time.Time
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	for fp, expires := range saved {
+		if expires.After(s.seen[fp]) {
+			s.seen[fp] = expires
+		}
+	}
+	return nil
+}
+
+// Claim records fp unless it was alerted on already, by this or another
+// request sharing the file, and its flight has not departed yet. It reports
+// whether fp was recorded, i.e. whether to alert on it. Fingerprints whose
+// flights have departed are dropped, and the store is saved merged with the
+// file so that concurrent requests do not overwrite each other's fingerprints.
+func (s *alertStore) Claim(fp string, expires time.Time) (bool, error) {
+	alertFileMu.Lock()
+	defer alertFileMu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if exp, ok := s.seen[fp]; ok && !exp.Before(now) {
+		return false, nil
+	}
+
+	for k, exp := range s.seen {
+		if exp.Before(now) {
+			delete(s.seen, k)
+		}
+	}
+	s.seen[fp] = expires
+
+	b, err := json.Marshal(s.seen)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(s.path, b, 0644)
+}
+
+// alertCooldown suppresses further alerts for a route for a while after one
+// was sent, with --alert-cooldown. A nil alertCooldown never suppresses.
+type alertCooldown struct {
+	period time.Duration
+	last   map[string]time.Time
+}
+
+func newAlertCooldown(period time.Duration) *alertCooldown {
+	if period <= 0 {
+		return nil
+	}
+	return &alertCooldown{period: period, last: make(map[string]time.Time)}
+}
+
+// Active reports whether route was alerted on less than the cooldown period before now.
+func (c *alertCooldown) Active(route string, now time.Time) bool {
+	if c == nil {
+		return false
+	}
+	last, ok := c.last[route]
+	return ok && now.Sub(last) < c.period
+}
+
+// Record starts the cooldown of route at now.
+func (c *alertCooldown) Record(route string, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.last[route] = now
+}
@@ -0,0 +1,145 @@
+package cheapflight
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnalertedAcrossCycles(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	deal := Message{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", route: "SFO → JFK", departure: day1}
+	cheaper := deal
+	cheaper.Price = 220
+	departed := deal
+	departed.Start, departed.departure = "2020-03-01", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		first  []Message
+		second []Message
+		want   int
+	}{
+		{name: "same deal again", first: []Message{deal}, second: []Message{deal}, want: 0},
+		{name: "price dropped", first: []Message{deal}, second: []Message{cheaper}, want: 1},
+		{name: "departed deal expired", first: []Message{departed}, second: []Message{departed, deal}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "alerts.json")
+			store, err := loadAlertStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := unalerted(tt.first, now, nil, store, discardLog); len(got) != len(tt.first) {
+				t.Fatalf("first cycle alerted on %d offers, want %d", len(got), len(tt.first))
+			}
+
+			// The second cycle reloads the store as a restarted watcher would.
+			store, err = loadAlertStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := unalerted(tt.second, now, nil, store, discardLog); len(got) != tt.want {
+				t.Errorf("second cycle alerted on %d offers, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertCooldown(t *testing.T) {
+	start := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	sfo := Message{Price: 250, Src: "SFO", Dst: "JFK", route: "SFO → JFK", departure: day1}
+	oak := Message{Price: 260, Src: "OAK", Dst: "JFK", route: "OAK → JFK", departure: day1}
+
+	cooldown := newAlertCooldown(2 * time.Hour)
+	steps := []struct {
+		after    time.Duration
+		messages []Message
+		want     []string
+	}{
+		{after: 0, messages: []Message{sfo}, want: []string{"SFO → JFK"}},
+		{after: 30 * time.Minute, messages: []Message{sfo, oak}, want: []string{"OAK → JFK"}},
+		{after: 90 * time.Minute, messages: []Message{sfo, oak}, want: nil},
+		{after: 2 * time.Hour, messages: []Message{sfo, oak}, want: []string{"SFO → JFK"}},
+		{after: 150 * time.Minute, messages: []Message{sfo, oak}, want: []string{"OAK → JFK"}},
+	}
+	for _, step := range steps {
+		// The watch loop records an alerted route's cooldown after notifying.
+		now := start.Add(step.after)
+		var got []string
+		for _, m := range unalerted(step.messages, now, cooldown, nil, discardLog) {
+			got = append(got, m.route)
+			cooldown.Record(m.route, now)
+		}
+		if strings.Join(got, ",") != strings.Join(step.want, ",") {
+			t.Errorf("after %s alerted on %q, want %q", step.after, got, step.want)
+		}
+	}
+
+	if newAlertCooldown(0).Active("SFO → JFK", start) {
+		t.Error("no cooldown suppressed an alert")
+	}
+}
+
+func TestAlertStoreSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	first, err := loadAlertStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := loadAlertStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two requests sharing the file each alert on their own deal.
+	for _, claim := range []struct {
+		store *alertStore
+		fp    string
+	}{{first, "sfo-jfk"}, {second, "oak-bos"}} {
+		if ok, err := claim.store.Claim(claim.fp, day1); err != nil || !ok {
+			t.Fatalf("Claim(%s) = %v, %v, want the first claim to succeed", claim.fp, ok, err)
+		}
+	}
+
+	// Neither overwrote the other's fingerprint, and neither alerts on it again.
+	if ok, _ := second.Claim("sfo-jfk", day1); ok {
+		t.Error("second request alerted on the first request's deal")
+	}
+	restarted, err := loadAlertStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fp := range []string{"sfo-jfk", "oak-bos"} {
+		if ok, _ := restarted.Claim(fp, day1); ok {
+			t.Errorf("%s was lost from the file", fp)
+		}
+	}
+
+	// Requests claiming the same deal at once alert on it exactly once.
+	stores := make([]*alertStore, 8)
+	for i := range stores {
+		if stores[i], err = loadAlertStore(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var claimed atomic.Int32
+	var wg sync.WaitGroup
+	for _, store := range stores {
+		wg.Add(1)
+		go func(store *alertStore) {
+			defer wg.Done()
+			if ok, err := store.Claim("lax-ord", day1); err == nil && ok {
+				claimed.Add(1)
+			}
+		}(store)
+	}
+	wg.Wait()
+	if n := claimed.Load(); n != 1 {
+		t.Errorf("%d requests alerted on the same deal, want 1", n)
+	}
+}
@@ -0,0 +1,85 @@
+package cheapflight
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+	search := func(api *stubAPI, f *fixtures) ([]Message, error) {
+		cfg := withStub(Config{}, api)
+		cfg.sessions.fixtures = f
+		return SearchOffers(args, "", cfg)
+	}
+
+	live := &stubAPI{
+		priceGraph: pricedEveryDay,
+		offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			price := 300.0
+			if args.Date.Equal(day1) {
+				price = 250
+			}
+			return []flights.FullOffer{offerOn(args.Date, price)}, &flights.PriceRange{Low: 300, High: 400}, nil
+		},
+		url: func(ctx context.Context, args flights.Args) (string, error) {
+			return "https://example.com/" + dateKey(args.Date), nil
+		},
+	}
+	recorded, err := search(live, newFixtures(dir, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaying serves every call from the recorded files, never reaching the API.
+	offline := &stubAPI{
+		priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) { return nil, errBoom },
+		offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			return nil, nil, errBoom
+		},
+		url: func(ctx context.Context, args flights.Args) (string, error) { return "", errBoom },
+	}
+	replayed, err := search(offline, newFixtures("", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priceGraph, offers, urls := offline.calls(); priceGraph+offers+urls != 0 {
+		t.Errorf("replay made %d price graph, %d offers and %d booking link calls, want none", priceGraph, offers, urls)
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed %+v, want the recorded %+v", replayed, recorded)
+	}
+	if len(replayed) != 1 || replayed[0].Url != "https://example.com/2030-03-01" || replayed[0].PriceRange == nil {
+		t.Errorf("replayed %+v, want the recorded offer, link and price range", replayed)
+	}
+
+	// Arguments that were never recorded fail instead of calling the API.
+	args.SrcAirports = []string{"OAK"}
+	if _, err := search(offline, newFixtures("", dir)); err == nil || !strings.Contains(err.Error(), "no recorded price-graph response") {
+		t.Errorf("got error %v, want no recorded response", err)
+	}
+}
+
+func TestNewFixtures(t *testing.T) {
+	tests := []struct {
+		record, replay string
+		want           *fixtures
+	}{
+		{},
+		{record: "rec", want: &fixtures{dir: "rec"}},
+		{replay: "rep", want: &fixtures{dir: "rep", replay: true}},
+	}
+	for _, tt := range tests {
+		if got := newFixtures(tt.record, tt.replay); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("newFixtures(%q, %q) = %+v, want %+v", tt.record, tt.replay, got, tt.want)
+		}
+	}
+	if _, err := ProcessFlags([]string{"--record=a", "--replay=b"}); err == nil {
+		t.Error("--record and --replay were accepted together")
+	}
+}
@@ -0,0 +1,105 @@
+package cheapflight
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStd redirects stdout and stderr to files for the rest of the test,
+// returning a func reading what was written to them.
+func captureStd(t *testing.T) func() string {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, stderr := os.Stdout, os.Stderr
+	var files []*os.File
+	for _, name := range []string{"stdout", "stderr"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	os.Stdout, os.Stderr = files[0], files[1]
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		for _, f := range files {
+			f.Close()
+		}
+	})
+	return func() string {
+		var out strings.Builder
+		for _, f := range files {
+			b, err := os.ReadFile(f.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			out.Write(b)
+		}
+		return out.String()
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	written := captureStd(t)
+	path := filepath.Join(t.TempDir(), "runway.log")
+	cfg, err := ProcessFlags([]string{"--log-file=" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.logger().Info("diagnostic line")
+	if err := cfg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "diagnostic line") {
+		t.Errorf("log file does not hold the log line:\n%s", b)
+	}
+	if out := written(); strings.Contains(out, "diagnostic line") {
+		t.Errorf("log line also written to stdout or stderr:\n%s", out)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0022 != 0 {
+		t.Errorf("log file mode = %v, %v, want it not writable by others", info.Mode(), err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxSize int64
+		writes  []string
+		want    string
+		wantOld string
+	}{
+		{name: "no limit", writes: []string{"aaaa\n", "bbbb\n"}, want: "aaaa\nbbbb\n"},
+		{name: "rotates past the limit", maxSize: 8, writes: []string{"aaaa\n", "bbbb\n"}, want: "bbbb\n", wantOld: "aaaa\n"},
+		{name: "within the limit", maxSize: 10, writes: []string{"aaaa\n", "bbbb\n"}, want: "aaaa\nbbbb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "runway.log")
+			f, err := openRotatingFile(path, tt.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.writes {
+				if _, err := f.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f.Close()
+
+			if b, _ := os.ReadFile(path); string(b) != tt.want {
+				t.Errorf("log file holds %q, want %q", b, tt.want)
+			}
+			if b, _ := os.ReadFile(path + ".1"); string(b) != tt.wantOld {
+				t.Errorf("rotated file holds %q, want %q", b, tt.wantOld)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package cheapflight
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+	"github.com/krisukox/google-flights-api/iata"
+)
+
+const earthRadiusMiles = 3958.8
+
+// nearbyAirports returns the airports with known coordinates within radius
+// miles of near, a city or IATA airport code, nearest first. It is how
+// --origin-near finds the airports to search from.
+func nearbyAirports(near string, radius float64) ([]string, error) {
+	center, ok := locationCoordinates(near)
+	if !ok {
+		return nil, fmt.Errorf("no coordinates known for %q, try a nearby major city or airport", near)
+	}
+
+	distances := make(map[string]float64)
+	var airports []string
+	for airport, coordinates := range airportCoordinates {
+		if d := distanceMiles(center, coordinates); d <= radius {
+			distances[airport] = d
+			airports = append(airports, airport)
+		}
+	}
+	sort.Slice(airports, func(i, j int) bool { return distances[airports[i]] < distances[airports[j]] })
+	return airports, nil
+}
+
+// locationCoordinates finds the position of an airport code, or of a city as
+// the midpoint of its airports with known coordinates.
+func locationCoordinates(location string) ([2]float64, bool) {
+	if coordinates, ok := airportCoordinates[strings.ToUpper(location)]; ok {
+		return coordinates, true
+	}
+
+	var sum [2]float64
+	var n float64
+	for airport, coordinates := range airportCoordinates {
+		if strings.EqualFold(iata.IATATimeZone(airport).City, location) {
+			sum[0] += coordinates[0]
+			sum[1] += coordinates[1]
+			n++
+		}
+	}
+	if n == 0 {
+		return [2]float64{}, false
+	}
+	return [2]float64{sum[0] / n, sum[1] / n}, true
+}
+
+// pricePerMile is the price of o divided by the great circle miles flown, there
+// and back for round trips, rounded to the cent. It is 0 when either airport
+// has no known coordinates.
+func pricePerMile(o flights.FullOffer, tripType flights.TripType) float64 {
+	src, okSrc := airportCoordinates[o.SrcAirportCode]
+	dst, okDst := airportCoordinates[o.DstAirportCode]
+	if !okSrc || !okDst || o.SrcAirportCode == o.DstAirportCode {
+		return 0
+	}
+	miles := distanceMiles(src, dst)
+	if tripType == flights.RoundTrip {
+		miles *= 2
+	}
+	return math.Round(o.Price/miles*100) / 100
+}
+
+// distanceMiles is the great circle distance between two latitude and
+// longitude pairs.
+func distanceMiles(a, b [2]float64) float64 {
+	lat1, lat2 := a[0]*math.Pi/180, b[0]*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b[1] - a[1]) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,90 @@
+package cheapflight
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestNearbyAirports(t *testing.T) {
+	tests := []struct {
+		near    string
+		radius  float64
+		want    []string
+		wantErr string
+	}{
+		{near: "san jose", radius: 50, want: []string{"SJC", "OAK", "SFO"}},
+		{near: "SJC", radius: 5, want: []string{"SJC"}},
+		{near: "sjc", radius: 40, want: []string{"SJC", "OAK", "SFO"}},
+		{near: "nowhere", radius: 100, wantErr: `no coordinates known for "nowhere"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.near, func(t *testing.T) {
+			got, err := nearbyAirports(tt.near, tt.radius)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("airports within %v miles of %s: %q, want %q", tt.radius, tt.near, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginNearFlags(t *testing.T) {
+	cfg, err := ProcessFlags([]string{"--origin-near=san jose", "--origin-radius=50"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"SJC", "OAK", "SFO"}; !reflect.DeepEqual(cfg.OriginAirports, want) {
+		t.Errorf("OriginAirports %q, want %q", cfg.OriginAirports, want)
+	}
+
+	for _, args := range [][]string{
+		{"--origin-near=san jose", "--origin-radius=0"},
+		{"--origin-near=san jose", "--routes-file=routes.txt"},
+		{"--origin-near=nowhere"},
+	} {
+		if _, err := ProcessFlags(args); err == nil {
+			t.Errorf("ProcessFlags(%q) accepted the flags", args)
+		}
+	}
+}
+
+func TestPricePerMile(t *testing.T) {
+	// SFO to JFK is 2580 great circle miles by the built in coordinates.
+	if d := distanceMiles(airportCoordinates["SFO"], airportCoordinates["JFK"]); math.Abs(d-2580) > 1 {
+		t.Fatalf("SFO to JFK is %.1f miles, want 2580", d)
+	}
+
+	tests := []struct {
+		name     string
+		src, dst string
+		price    float64
+		tripType flights.TripType
+		want     float64
+	}{
+		{name: "one way", src: "SFO", dst: "JFK", price: 258, tripType: flights.OneWay, want: 0.1},
+		{name: "round trip flies both ways", src: "SFO", dst: "JFK", price: 258, tripType: flights.RoundTrip, want: 0.05},
+		{name: "rounded to the cent", src: "SFO", dst: "JFK", price: 300, tripType: flights.OneWay, want: 0.12},
+		{name: "unknown airport", src: "SFO", dst: "XXX", price: 258, tripType: flights.OneWay, want: 0},
+		{name: "same airport", src: "SFO", dst: "SFO", price: 258, tripType: flights.OneWay, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := flights.FullOffer{Offer: flights.Offer{Price: tt.price}, SrcAirportCode: tt.src, DstAirportCode: tt.dst}
+			if got := pricePerMile(o, tt.tripType); got != tt.want {
+				t.Errorf("pricePerMile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
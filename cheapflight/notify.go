@@ -0,0 +1,158 @@
+package cheapflight
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	notifyModeAll      = "all"
+	notifyModeFallback = "fallback"
+)
+
+// errNothingSent is returned by a channel that had nothing to send, as none of
+// the messages beat the best price so far or the target. It does not count as
+// success for the fallback --notify-mode, so the next channel is tried.
+var errNothingSent = errors.New("nothing to send")
+
+// alert is what one check has to notify about.
+type alert struct {
+	messages []Message
+	smsNum   string
+	target   float64
+	minFound float64
+}
+
+// notifiers sends an alert through each --notify channel.
+var notifiers = map[string]func(alert, Config) error{
+	"webhook": notifyWebhook,
+	"sms":     notifySMS,
+	"email":   notifyEmail,
+}
+
+func notifierNames() []string {
+	return []string{"webhook", "sms", "email"}
+}
+
+// notifiers returns the --notify channels, by default the webhook when
+// --webhook-url is set followed by SMS.
+func (c Config) notifiers() []string {
+	if len(c.Notify) > 0 {
+		return c.Notify
+	}
+	if c.WebhookURL != "" {
+		return []string{"webhook", "sms"}
+	}
+	return []string{"sms"}
+}
+
+// checkNotifiers validates channel names given with --notify or in a routes
+// file, and that the channels that need a destination have one.
+func (c Config) checkNotifiers(names []string) error {
+	for _, name := range names {
+		if _, ok := notifiers[name]; !ok {
+			return fmt.Errorf("unknown channel %q, expected one of %s", name, strings.Join(notifierNames(), ", "))
+		}
+		if name == "webhook" && c.WebhookURL == "" {
+			return errors.New("the webhook channel needs --webhook-url")
+		}
+		if name == "email" && len(c.EmailTo) == 0 {
+			return errors.New("the email channel needs --email-to")
+		}
+	}
+	return nil
+}
+
+// notify sends messages through their channels in order: those of their route
+// in the routes file, or else the --notify channels. With the fallback
+// --notify-mode it stops at the first channel that succeeds, so a later
+// channel only fires when the ones before it failed or had nothing to send.
+// The fallback applies to the alert as a whole, not to each message: a channel
+// failing part way, e.g. SMS after some texts went out, is not undone, and
+// the next channel sends every message again.
+func notify(messages []Message, cfg Config, SMSNum string, target, minFound float64) {
+	var order []string
+	groups := make(map[string][]Message)
+	for _, m := range messages {
+		names := m.notify
+		if len(names) == 0 {
+			names = cfg.notifiers()
+		}
+		key := strings.Join(names, ",")
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], m)
+	}
+
+	for _, key := range order {
+		a := alert{messages: groups[key], smsNum: SMSNum, target: target, minFound: minFound}
+		for _, name := range strings.Split(key, ",") {
+			err := notifiers[name](a, cfg)
+			if err == nil && cfg.NotifyMode == notifyModeFallback {
+				break
+			}
+			if err != nil && !errors.Is(err, errNothingSent) {
+				cfg.logger().Error("notification failed", "notifier", name, "err", err)
+			}
+		}
+	}
+}
+
+func notifyWebhook(a alert, cfg Config) error {
+	var payload bytes.Buffer
+	shared := a.messages
+	if cfg.Anonymize {
+		shared = anonymize(a.messages)
+	}
+	if err := cfg.webhookRenderer().Render(&payload, shared); err != nil {
+		return err
+	}
+	if err := SendWebhook(payload.Bytes(), cfg.WebhookURL); err != nil {
+		return err
+	}
+	cfg.logger().Info("webhook sent")
+	return nil
+}
+
+func notifySMS(a alert, cfg Config) error {
+	bodies := a.bodies()
+	if len(bodies) == 0 {
+		return errNothingSent
+	}
+	for _, body := range bodies {
+		if err := sendSMS(body, a.smsNum); err != nil {
+			return err
+		}
+		cfg.logger().Info("SMS sent")
+	}
+	return nil
+}
+
+func notifyEmail(a alert, cfg Config) error {
+	bodies := a.bodies()
+	if len(bodies) == 0 {
+		return errNothingSent
+	}
+	if err := sendEmail(strings.Join(bodies, "\n\n"), cfg.EmailTo); err != nil {
+		return err
+	}
+	cfg.logger().Info("email sent")
+	return nil
+}
+
+// bodies are the SMS texts for the messages that beat the best price found so
+// far or the target price.
+func (a alert) bodies() []string {
+	var bodies []string
+	for _, message := range a.messages {
+		if float64(message.Price) < a.minFound {
+			bodies = append(bodies, FormatMessageBody(message))
+		} else if float64(message.Price) < a.target {
+			bodies = append(bodies, FormatMessageBodyTarget(message, a.target))
+		}
+	}
+	return bodies
+}
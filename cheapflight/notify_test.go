@@ -0,0 +1,148 @@
+package cheapflight
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeNotifiers replaces the channels for the test with ones returning the
+// given results, recording the order they were called in.
+func fakeNotifiers(t *testing.T, results map[string]error) *[]string {
+	t.Helper()
+	saved := make(map[string]func(alert, Config) error)
+	for name, fn := range notifiers {
+		saved[name] = fn
+	}
+	t.Cleanup(func() {
+		for name, fn := range saved {
+			notifiers[name] = fn
+		}
+	})
+
+	var called []string
+	for name := range notifiers {
+		name := name
+		notifiers[name] = func(alert, Config) error {
+			called = append(called, name)
+			return results[name]
+		}
+	}
+	return &called
+}
+
+func TestNotifyFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		channels   []string
+		results    map[string]error
+		wantCalled []string
+		wantLogged bool
+	}{
+		{name: "first fails, fallback succeeds", mode: notifyModeFallback, channels: []string{"webhook", "email"},
+			results: map[string]error{"webhook": errBoom}, wantCalled: []string{"webhook", "email"}, wantLogged: true},
+		{name: "first succeeds", mode: notifyModeFallback, channels: []string{"webhook", "email"},
+			wantCalled: []string{"webhook"}},
+		{name: "first has nothing to send", mode: notifyModeFallback, channels: []string{"sms", "webhook"},
+			results: map[string]error{"sms": errNothingSent}, wantCalled: []string{"sms", "webhook"}},
+		{name: "every channel fails", mode: notifyModeFallback, channels: []string{"webhook", "sms", "email"},
+			results: map[string]error{"webhook": errBoom, "sms": errBoom, "email": errBoom}, wantCalled: []string{"webhook", "sms", "email"}, wantLogged: true},
+		{name: "all fire regardless", mode: notifyModeAll, channels: []string{"webhook", "email"},
+			wantCalled: []string{"webhook", "email"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := fakeNotifiers(t, tt.results)
+			var logged bytes.Buffer
+			cfg := Config{Notify: tt.channels, NotifyMode: tt.mode, log: slog.New(slog.NewTextHandler(&logged, nil))}
+
+			notify([]Message{{Price: 250, Src: "SFO", Dst: "JFK"}}, cfg, "", 0, math.Inf(1))
+
+			if strings.Join(*called, ",") != strings.Join(tt.wantCalled, ",") {
+				t.Errorf("called %q, want %q", *called, tt.wantCalled)
+			}
+			if got := strings.Contains(logged.String(), "notification failed"); got != tt.wantLogged {
+				t.Errorf("logged a failure %v, want %v:\n%s", got, tt.wantLogged, logged.String())
+			}
+		})
+	}
+}
+
+func TestNothingSent(t *testing.T) {
+	// Neither message beats the best price so far nor the target.
+	a := alert{messages: []Message{{Price: 300}, {Price: 320}}, target: 250, minFound: 280}
+	for name, fn := range map[string]func(alert, Config) error{"sms": notifySMS, "email": notifyEmail} {
+		if err := fn(a, Config{}); err != errNothingSent {
+			t.Errorf("%s returned %v, want errNothingSent", name, err)
+		}
+	}
+}
+
+func TestNotifyPerRoute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.txt")
+	if err := os.WriteFile(path, []byte("SFO JFK webhook\nOAK BOS email\nLAX ORD\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := loadRoutes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := withStub(Config{Notify: []string{"sms"}, WebhookURL: "https://example.com/hook", EmailTo: []string{"a@example.com"}}, routeStub())
+	for _, r := range routes {
+		if err := cfg.checkNotifiers(r.notifiers()); err != nil {
+			t.Fatalf("route %s: %v", r, err)
+		}
+	}
+	messages, err := searchRoutes(routes, routeArgs(), "", cfg, make(map[route][]float64), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeNotifiers(t, nil)
+	delivered := make(map[string][]string)
+	for name := range notifiers {
+		name := name
+		notifiers[name] = func(a alert, _ Config) error {
+			for _, m := range a.messages {
+				delivered[name] = append(delivered[name], m.route)
+			}
+			return nil
+		}
+	}
+	notify(messages, cfg, "", 0, math.Inf(1))
+
+	want := map[string][]string{
+		"webhook": {"SFO → JFK"},
+		"email":   {"OAK → BOS"},
+		// Routes without channels of their own alert through --notify.
+		"sms": {"LAX → ORD"},
+	}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered %q, want %q", delivered, want)
+	}
+}
+
+func TestCheckNotifiers(t *testing.T) {
+	cfg := Config{WebhookURL: "https://example.com/hook"}
+	tests := []struct {
+		names   []string
+		wantErr string
+	}{
+		{names: []string{"webhook", "sms"}},
+		{names: []string{"slack"}, wantErr: `unknown channel "slack"`},
+		{names: []string{"email"}, wantErr: "needs --email-to"},
+	}
+	for _, tt := range tests {
+		err := cfg.checkNotifiers(tt.names)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("checkNotifiers(%q) = %v, want %q", tt.names, err, tt.wantErr)
+		}
+	}
+}
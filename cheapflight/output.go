@@ -0,0 +1,64 @@
+package cheapflight
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+const (
+	recordOffer  = "offer"
+	recordResult = "result"
+)
+
+// ndjsonRecord is one line of the ndjson format. Type tells the offers streamed
+// while searching, "offer", from the results reported once a check is done,
+// "result", which repeat the best of those offers.
+type ndjsonRecord struct {
+	Type string `json:"type"`
+	Message
+}
+
+// offerStream writes one JSON object per line, flushing after every offer so
+// consumers such as jq see results as soon as they are found.
+type offerStream struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	segments  bool
+	anonymize bool
+}
+
+func newOfferStream(w io.Writer, segments, anonymize bool) *offerStream {
+	return &offerStream{w: bufio.NewWriter(w), segments: segments, anonymize: anonymize}
+}
+
+func (s *offerStream) Emit(offers []flights.FullOffer) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range offers {
+		m := newMessage(o, "")
+		if s.segments {
+			m.Segments = newSegments(o)
+		}
+		if s.anonymize {
+			m = anonymizeMessage(m)
+		}
+		b, err := json.Marshal(ndjsonRecord{Type: recordOffer, Message: m})
+		if err != nil {
+			return err
+		}
+		s.w.Write(b)
+		s.w.WriteByte('\n')
+		if err := s.w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
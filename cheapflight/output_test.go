@@ -0,0 +1,86 @@
+package cheapflight
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestOfferStreamEmitsIncrementally(t *testing.T) {
+	release := make(chan struct{})
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		if args.Date.Equal(day2) {
+			<-release
+		}
+		return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+	}}
+	r, w := io.Pipe()
+	cfg := withStub(Config{Format: formatNDJSON}, api)
+	cfg.stream = cfg.newOfferStream(w)
+
+	done := make(chan error)
+	go func() {
+		trips := []datedTrip{{departure: day1, ret: day1.AddDate(0, 0, 3)}, {departure: day2, ret: day2.AddDate(0, 0, 3)}}
+		_, err := queryTrips(context.Background(), cfg.session(), flights.PriceGraphArgs{Options: roundTrip()}, trips, "", cfg)
+		w.Close()
+		done <- err
+	}()
+
+	lines := bufio.NewScanner(r)
+	wantDates := []time.Time{day1, day2}
+	for i, want := range wantDates {
+		if !lines.Scan() {
+			t.Fatalf("stream ended after %d lines", i)
+		}
+		var record struct {
+			Type string `json:"type"`
+			End  string `json:"end"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if wantEnd := dateKey(want.AddDate(0, 0, 3)); record.Type != recordOffer || record.End != wantEnd {
+			t.Errorf("line %d = %s, want an offer returning %s", i+1, lines.Text(), wantEnd)
+		}
+		if i == 0 {
+			// The second date is still being queried, so the first offer
+			// arrived before the search finished.
+			close(release)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNDJSONRecordTypes(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := Config{Format: formatNDJSON}
+	if err := cfg.newOfferStream(&buf).Emit([]flights.FullOffer{offerOn(day1, 120)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := (ndjsonRenderer{}).Render(&buf, []Message{{Price: 120}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record ndjsonRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, record.Type)
+	}
+	if len(types) != 2 || types[0] != recordOffer || types[1] != recordResult {
+		t.Errorf("record types = %v, want [offer result]", types)
+	}
+	if (Config{Format: formatJSON}).newOfferStream(&buf) != nil {
+		t.Error("non-ndjson formats stream offers")
+	}
+}
@@ -0,0 +1,56 @@
+package cheapflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// preset computes the departure and return dates of a --preset from today.
+type preset func(today time.Time) (start, end time.Time)
+
+// presets are the --preset values. Each searches fixed dates.
+var presets = map[string]preset{
+	// weekend departs the upcoming Saturday and returns on Sunday.
+	"weekend": func(today time.Time) (time.Time, time.Time) {
+		sat := nextWeekday(today, time.Saturday)
+		return sat, sat.AddDate(0, 0, 1)
+	},
+	// long-weekend departs the upcoming Friday and returns on Monday.
+	"long-weekend": func(today time.Time) (time.Time, time.Time) {
+		fri := nextWeekday(today, time.Friday)
+		return fri, fri.AddDate(0, 0, 3)
+	},
+	// week departs the upcoming Saturday and returns a week later.
+	"week": func(today time.Time) (time.Time, time.Time) {
+		sat := nextWeekday(today, time.Saturday)
+		return sat, sat.AddDate(0, 0, 7)
+	},
+}
+
+func presetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nextWeekday returns the first day on or after today falling on day.
+func nextWeekday(today time.Time, day time.Weekday) time.Time {
+	return today.AddDate(0, 0, (int(day)-int(today.Weekday())+7)%7)
+}
+
+// presetDates resolves the named preset relative to now. Like parsed date
+// arguments the dates are midnight UTC of now's calendar day.
+func presetDates(name string, now time.Time) (start, end time.Time, err error) {
+	p, ok := presets[name]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown --preset %q, expected one of %s", name, strings.Join(presetNames(), ", "))
+	}
+	y, m, d := now.Date()
+	start, end = p(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	return start, end, nil
+}
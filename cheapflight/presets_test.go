@@ -0,0 +1,44 @@
+package cheapflight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresetDates(t *testing.T) {
+	// 2030-03-06 is a Wednesday.
+	wednesday := time.Date(2030, 3, 6, 18, 30, 0, 0, time.FixedZone("PST", -8*60*60))
+	tests := []struct {
+		preset    string
+		now       time.Time
+		wantStart string
+		wantEnd   string
+	}{
+		{preset: "weekend", now: wednesday, wantStart: "2030-03-09", wantEnd: "2030-03-10"},
+		{preset: "weekend", now: wednesday.AddDate(0, 0, 3), wantStart: "2030-03-09", wantEnd: "2030-03-10"},
+		{preset: "weekend", now: wednesday.AddDate(0, 0, 4), wantStart: "2030-03-16", wantEnd: "2030-03-17"},
+		{preset: "long-weekend", now: wednesday, wantStart: "2030-03-08", wantEnd: "2030-03-11"},
+		{preset: "week", now: wednesday, wantStart: "2030-03-09", wantEnd: "2030-03-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset+" from "+tt.now.Weekday().String(), func(t *testing.T) {
+			withArgs(t, "ignored", "ignored", "ignored", "SFO", "JFK", "default", "default", "RoundTrip", "default", "default", "default", "", "")
+			now := tt.now
+			cfg := Config{Preset: tt.preset, clock: func() time.Time { return now }}
+
+			args, _, _, _, err := ProcessArgs(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dateKey(args.RangeStartDate); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := dateKey(args.RangeEndDate); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+			if args.TripLength != fixedDates {
+				t.Errorf("trip length = %d, want fixed dates", args.TripLength)
+			}
+		})
+	}
+}
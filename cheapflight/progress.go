@@ -0,0 +1,56 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// progress redraws a "dates searched" counter on one terminal line. A nil
+// progress draws nothing, so callers need not check whether it is enabled.
+type progress struct {
+	mu    sync.Mutex
+	w     io.Writer
+	done  int
+	total int
+}
+
+// progress returns a progress counter over total dates, or nil with --quiet
+// or when stderr is not a terminal.
+func (c Config) progress(total int) *progress {
+	if c.Quiet || total == 0 || !isTerminal(os.Stderr) {
+		return nil
+	}
+	return &progress{w: os.Stderr, total: total}
+}
+
+// progressFraction is the share of total that is done, between 0 and 1.
+func progressFraction(done, total int) float64 {
+	if total <= 0 {
+		return 1
+	}
+	if done > total {
+		done = total
+	}
+	return float64(done) / float64(total)
+}
+
+// Step records one more date as searched.
+func (p *progress) Step() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	fmt.Fprintf(p.w, "\rsearched %d/%d dates (%.0f%%)", p.done, p.total, 100*progressFraction(p.done, p.total))
+}
+
+// Finish ends the progress line.
+func (p *progress) Finish() {
+	if p == nil {
+		return
+	}
+	fmt.Fprintln(p.w)
+}
@@ -0,0 +1,43 @@
+package cheapflight
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProgressFraction(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        float64
+	}{
+		{done: 0, total: 4, want: 0},
+		{done: 1, total: 4, want: 0.25},
+		{done: 4, total: 4, want: 1},
+		{done: 5, total: 4, want: 1},
+		{done: 0, total: 0, want: 1},
+	}
+	for _, tt := range tests {
+		if got := progressFraction(tt.done, tt.total); got != tt.want {
+			t.Errorf("progressFraction(%d, %d) = %v, want %v", tt.done, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestProgressStep(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progress{w: &buf, total: 3}
+	p.Step()
+	p.Step()
+	p.Finish()
+	if want := "\rsearched 1/3 dates (33%)\rsearched 2/3 dates (67%)\n"; buf.String() != want {
+		t.Errorf("drew %q, want %q", buf.String(), want)
+	}
+
+	// A disabled progress is nil and draws nothing.
+	var disabled *progress
+	disabled.Step()
+	disabled.Finish()
+	if cfg := (Config{Quiet: true}); cfg.progress(3) != nil {
+		t.Error("--quiet still shows progress")
+	}
+}
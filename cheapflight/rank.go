@@ -0,0 +1,94 @@
+package cheapflight
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// offerLess reports whether offer a should be preferred over offer b.
+type offerLess func(a, b flights.FullOffer) bool
+
+// lessOffer orders offers by price, breaking ties by --preferred-airlines.
+func (c Config) lessOffer(a, b flights.FullOffer) bool {
+	if a.Price != b.Price {
+		return a.Price < b.Price
+	}
+	return airlineRank(a, c.PreferredAirlines) < airlineRank(b, c.PreferredAirlines)
+}
+
+// bestOffer selects the cheapest offer by lessOffer and then, with
+// --airport-preference, the offer from the most preferred airport among those
+// costing at most --price-tolerance more than that cheapest offer. Measuring
+// the tolerance from the one cheapest price, rather than between pairs of
+// offers, keeps the choice independent of the order of the offers.
+func (c Config) bestOffer(offers []flights.FullOffer, excludedAirline string) flights.FullOffer {
+	cheapest := selectBestOffer(offers, excludedAirline, c.lessOffer)
+	if cheapest.Price == 0 || len(c.AirportPreference) == 0 {
+		return cheapest
+	}
+
+	var within []flights.FullOffer
+	for _, o := range offers {
+		if o.Price != 0 && o.Price-cheapest.Price <= c.PriceTolerance {
+			within = append(within, o)
+		}
+	}
+	return selectBestOffer(within, excludedAirline, func(a, b flights.FullOffer) bool {
+		rankA, rankB := airportRank(a, c.AirportPreference), airportRank(b, c.AirportPreference)
+		if rankA != rankB {
+			return rankA < rankB
+		}
+		return c.lessOffer(a, b)
+	})
+}
+
+const (
+	sortRoute        = "route"
+	sortPrice        = "price"
+	sortPricePerMile = "price-per-mile"
+)
+
+// sortMessages orders results by --sort. The route order keeps them in the
+// order they were searched. Results without a price per mile, for airports
+// with unknown coordinates, go last under price-per-mile.
+func sortMessages(messages []Message, by string) {
+	switch by {
+	case sortPrice:
+		sort.SliceStable(messages, func(i, j int) bool { return messages[i].Price < messages[j].Price })
+	case sortPricePerMile:
+		sort.SliceStable(messages, func(i, j int) bool {
+			a, b := messages[i].PricePerMile, messages[j].PricePerMile
+			if a == 0 || b == 0 {
+				return b == 0 && a != 0
+			}
+			return a < b
+		})
+	}
+}
+
+// airlineRank is the position of the offer's first airline in preferred, or
+// len(preferred) when the airline is not preferred.
+func airlineRank(o flights.FullOffer, preferred []string) int {
+	if len(o.Flight) == 0 {
+		return len(preferred)
+	}
+	for i, airline := range preferred {
+		if strings.EqualFold(airline, o.Flight[0].AirlineName) {
+			return i
+		}
+	}
+	return len(preferred)
+}
+
+// airportRank is the best position of the offer's source or destination airport
+// in preferred, or len(preferred) when neither airport is preferred.
+func airportRank(o flights.FullOffer, preferred []string) int {
+	for i, airport := range preferred {
+		if strings.EqualFold(airport, o.SrcAirportCode) || strings.EqualFold(airport, o.DstAirportCode) {
+			return i
+		}
+	}
+	return len(preferred)
+}
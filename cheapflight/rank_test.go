@@ -0,0 +1,147 @@
+package cheapflight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func flownBy(airline string, price float64) flights.FullOffer {
+	o := offerOn(day1, price)
+	o.Flight = []flights.Flight{{AirlineName: airline}}
+	return o
+}
+
+func TestPreferredAirlines(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred []string
+		offers    []flights.FullOffer
+		want      string
+	}{
+		{
+			name:      "preferred wins a tie",
+			preferred: []string{"Delta"},
+			offers:    []flights.FullOffer{flownBy("United", 300), flownBy("Delta", 300)},
+			want:      "Delta",
+		},
+		{
+			name:      "order of preference",
+			preferred: []string{"Alaska", "delta"},
+			offers:    []flights.FullOffer{flownBy("Delta", 300), flownBy("Alaska", 300), flownBy("United", 300)},
+			want:      "Alaska",
+		},
+		{
+			name:      "cheaper beats preferred",
+			preferred: []string{"Delta"},
+			offers:    []flights.FullOffer{flownBy("Delta", 300), flownBy("United", 299)},
+			want:      "United",
+		},
+		{
+			name:   "no preference keeps the first",
+			offers: []flights.FullOffer{flownBy("United", 300), flownBy("Delta", 300)},
+			want:   "United",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{PreferredAirlines: tt.preferred}
+			best := selectBestOffer(tt.offers, "", cfg.lessOffer)
+			if got := best.Flight[0].AirlineName; got != tt.want {
+				t.Errorf("selected %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAirportPreference(t *testing.T) {
+	from := func(src string, price float64) flights.FullOffer {
+		o := offerOn(day1, price)
+		o.SrcAirportCode = src
+		return o
+	}
+	tests := []struct {
+		name      string
+		tolerance float64
+		offers    []flights.FullOffer
+		want      string
+	}{
+		{name: "SFO within $20 of a $5 cheaper OAK", tolerance: 20, offers: []flights.FullOffer{from("OAK", 295), from("SFO", 300)}, want: "SFO 300"},
+		{name: "SFO beyond the tolerance", tolerance: 20, offers: []flights.FullOffer{from("OAK", 270), from("SFO", 300)}, want: "OAK 270"},
+		{name: "no tolerance breaks ties only", offers: []flights.FullOffer{from("OAK", 295), from("SFO", 300)}, want: "OAK 295"},
+		{name: "tie at no tolerance", offers: []flights.FullOffer{from("OAK", 300), from("SFO", 300)}, want: "SFO 300"},
+		// Pairwise, SJC 290 beats SFO 305 and SFO 305 beats OAK 300 within
+		// $10, yet OAK 300 beats SJC 290 within $10: the tolerance is measured
+		// from the cheapest price so the order of the offers does not matter.
+		{name: "chain of offers", tolerance: 10, offers: []flights.FullOffer{from("SFO", 305), from("OAK", 300), from("SJC", 290)}, want: "OAK 300"},
+		{name: "chain of offers reordered", tolerance: 10, offers: []flights.FullOffer{from("SJC", 290), from("OAK", 300), from("SFO", 305)}, want: "OAK 300"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{AirportPreference: []string{"SFO", "OAK"}, PriceTolerance: tt.tolerance}
+			best := cfg.bestOffer(tt.offers, "")
+			if got := fmt.Sprintf("%s %v", best.SrcAirportCode, best.Price); got != tt.want {
+				t.Errorf("selected %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLessOfferStrictWeakOrder checks the properties sort and the bounded heap rely on.
+func TestLessOfferStrictWeakOrder(t *testing.T) {
+	cfg := Config{PreferredAirlines: []string{"Delta"}, AirportPreference: []string{"SFO"}, PriceTolerance: 50}
+	var offers []flights.FullOffer
+	for _, airline := range []string{"Delta", "United"} {
+		for _, price := range []float64{100, 120, 140, 160} {
+			o := flownBy(airline, price)
+			o.SrcAirportCode = map[bool]string{true: "SFO", false: "OAK"}[price == 140]
+			offers = append(offers, o)
+		}
+	}
+	for _, a := range offers {
+		if cfg.lessOffer(a, a) {
+			t.Errorf("lessOffer(a, a) for %v", a.Price)
+		}
+		for _, b := range offers {
+			if cfg.lessOffer(a, b) && cfg.lessOffer(b, a) {
+				t.Errorf("lessOffer is not asymmetric for %v and %v", a.Price, b.Price)
+			}
+			for _, c := range offers {
+				if cfg.lessOffer(a, b) && cfg.lessOffer(b, c) && !cfg.lessOffer(a, c) {
+					t.Errorf("lessOffer is not transitive for %v, %v and %v", a.Price, b.Price, c.Price)
+				}
+			}
+		}
+	}
+}
+
+func TestSortMessages(t *testing.T) {
+	messages := []Message{
+		{Src: "SFO", Price: 300, PricePerMile: 0.12},
+		{Src: "OAK", Price: 250},
+		{Src: "SJC", Price: 400, PricePerMile: 0.08},
+		{Src: "LAX", Price: 200, PricePerMile: 0.3},
+	}
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{by: sortRoute, want: "SFO OAK SJC LAX"},
+		{by: sortPrice, want: "LAX OAK SFO SJC"},
+		// Results without a price per mile go last.
+		{by: sortPricePerMile, want: "SJC SFO LAX OAK"},
+	}
+	for _, tt := range tests {
+		sorted := append([]Message{}, messages...)
+		sortMessages(sorted, tt.by)
+		var got []string
+		for _, m := range sorted {
+			got = append(got, m.Src)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("sorted by %s: %s, want %s", tt.by, strings.Join(got, " "), tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+const (
+	ecbRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	// ecbRatesTTL is how long fetched rates are reused; the ECB publishes once a day.
+	ecbRatesTTL = 12 * time.Hour
+)
+
+// RateProvider converts prices between currencies for --display-currencies.
+type RateProvider interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(from, to currency.Unit) (float64, error)
+}
+
+// rateTable holds the value of one unit of a common base currency in each
+// currency, keyed by ISO code.
+type rateTable map[string]float64
+
+func (t rateTable) Rate(from, to currency.Unit) (float64, error) {
+	fromRate, ok := t[from.String()]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := t[to.String()]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// loadRateTable reads a --rates-file, a JSON object of currency codes to the
+// value of one unit of a common base currency, e.g. {"EUR": 1, "USD": 1.08}.
+func loadRateTable(path string) (rateTable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var table rateTable
+	if err := json.Unmarshal(b, &table); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for code, rate := range table {
+		if rate <= 0 {
+			return nil, fmt.Errorf("%s: rate for %s must be positive", path, code)
+		}
+	}
+	return table, nil
+}
+
+// ecbRates fetches the European Central Bank's daily reference rates, which
+// are quoted against the euro, caching them for ecbRatesTTL.
+type ecbRates struct {
+	mu      sync.Mutex
+	table   rateTable
+	fetched time.Time
+}
+
+func (r *ecbRates) Rate(from, to currency.Unit) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.table == nil || time.Since(r.fetched) > ecbRatesTTL {
+		table, err := fetchECBRates()
+		if err != nil {
+			return 0, fmt.Errorf("fetching exchange rates: %w", err)
+		}
+		r.table, r.fetched = table, time.Now()
+	}
+	return r.table.Rate(from, to)
+}
+
+func fetchECBRates() (rateTable, error) {
+	resp, err := http.Get(ecbRatesURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", ecbRatesURL, resp.Status)
+	}
+
+	var doc struct {
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube>Cube>Cube"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	table := rateTable{currency.EUR.String(): 1}
+	for _, rate := range doc.Rates {
+		table[rate.Currency] = rate.Rate
+	}
+	return table, nil
+}
+
+// convertPrices fills m.Prices with m's price in each --display-currencies
+// currency, rounded to cents.
+func convertPrices(m *Message, currencies []currency.Unit, rates RateProvider) error {
+	from, err := currency.ParseISO(m.currency())
+	if err != nil {
+		return err
+	}
+
+	m.Prices = make(map[string]float64, len(currencies))
+	for _, to := range currencies {
+		rate, err := rates.Rate(from, to)
+		if err != nil {
+			return err
+		}
+		m.Prices[to.String()] = math.Round(float64(m.Price)*rate*100) / 100
+	}
+	return nil
+}
+
+// formatPrices lists the converted prices of m by currency code.
+func formatPrices(m Message) string {
+	var codes []string
+	for code := range m.Prices {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var prices []string
+	for _, code := range codes {
+		prices = append(prices, fmt.Sprintf("%.2f %s", m.Prices[code], code))
+	}
+	return strings.Join(prices, ", ")
+}
@@ -0,0 +1,42 @@
+package cheapflight
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDisplayCurrencies(t *testing.T) {
+	ratesFile := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(ratesFile, []byte(`{"EUR": 1, "USD": 1.25, "GBP": 0.8}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ProcessFlags([]string{"--display-currencies=USD,EUR,GBP", "--rates-file=" + ratesFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		m    Message
+		want map[string]float64
+	}{
+		{m: Message{Price: 100}, want: map[string]float64{"USD": 100, "EUR": 80, "GBP": 64}},
+		{m: Message{Price: 250, Currency: "EUR"}, want: map[string]float64{"USD": 312.5, "EUR": 250, "GBP": 200}},
+		{m: Message{Price: 99, Currency: "GBP"}, want: map[string]float64{"USD": 154.69, "EUR": 123.75, "GBP": 99}},
+	}
+	for _, tt := range tests {
+		m := tt.m
+		if err := convertPrices(&m, cfg.DisplayCurrencies, cfg.Rates); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(m.Prices, tt.want) {
+			t.Errorf("%d %s converted to %v, want %v", m.Price, m.currency(), m.Prices, tt.want)
+		}
+	}
+
+	m := Message{Price: 100, Currency: "JPY"}
+	if err := convertPrices(&m, cfg.DisplayCurrencies, cfg.Rates); err == nil {
+		t.Error("converted a currency missing from the rate table")
+	}
+}
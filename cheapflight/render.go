@@ -0,0 +1,253 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatText         = "text"
+	formatNDJSON       = "ndjson"
+	formatJSON         = "json"
+	formatPrettyJSON   = "pretty-json"
+	formatCSV          = "csv"
+	formatTable        = "table"
+	formatHTML         = "html"
+	formatMarkdown     = "markdown"
+	formatSlack        = "slack"
+	formatICS          = "ics"
+	formatEnv          = "env"
+	formatInflux       = "influx"
+	formatQR           = "qr"
+	formatTSV          = "tsv"
+	formatProtobuf     = "protobuf"
+	formatYAML         = "yaml"
+	formatDiscord      = "discord"
+	formatGnuplot      = "gnuplot"
+	formatCombinedJSON = "combined-json"
+	formatFlatJSON     = "flat-json"
+	formatKML          = "kml"
+	formatBadge        = "badge"
+	formatOrg          = "org"
+	formatTOML         = "toml"
+	formatLaTeX        = "latex"
+	formatSQLiteExport = "sqlite-export"
+)
+
+// Renderer presents search results. Each --format value maps to one Renderer.
+type Renderer interface {
+	Render(w io.Writer, messages []Message) error
+}
+
+// renderers builds the Renderer for each --format value from the request config.
+var renderers = map[string]func(Config) Renderer{
+	formatText: func(c Config) Renderer {
+		if c.OutputTemplate != nil {
+			return templateRenderer{tmpl: c.OutputTemplate}
+		}
+		if c.CompactFields != nil {
+			return compactRenderer{fields: c.CompactFields, colors: c.fareColors()}
+		}
+		return textRenderer{}
+	},
+	formatNDJSON:       func(Config) Renderer { return ndjsonRenderer{} },
+	formatJSON:         func(Config) Renderer { return jsonRenderer{} },
+	formatPrettyJSON:   func(c Config) Renderer { return prettyJSONRenderer{color: c.useColor()} },
+	formatCSV:          func(Config) Renderer { return csvRenderer{} },
+	formatTable:        func(c Config) Renderer { return tableRenderer{colors: c.fareColors()} },
+	formatHTML:         func(Config) Renderer { return htmlRenderer{} },
+	formatMarkdown:     func(Config) Renderer { return markdownRenderer{} },
+	formatSlack:        func(Config) Renderer { return slackRenderer{} },
+	formatICS:          func(Config) Renderer { return icsRenderer{} },
+	formatEnv:          func(Config) Renderer { return envRenderer{} },
+	formatInflux:       func(Config) Renderer { return influxRenderer{now: time.Now} },
+	formatQR:           func(Config) Renderer { return qrRenderer{} },
+	formatTSV:          func(Config) Renderer { return tsvRenderer{} },
+	formatProtobuf:     func(Config) Renderer { return protobufRenderer{} },
+	formatYAML:         func(Config) Renderer { return yamlRenderer{} },
+	formatDiscord:      func(Config) Renderer { return discordRenderer{} },
+	formatGnuplot:      func(Config) Renderer { return gnuplotRenderer{} },
+	formatCombinedJSON: newCombinedJSONRenderer,
+	formatFlatJSON:     func(Config) Renderer { return flatJSONRenderer{} },
+	formatKML:          func(Config) Renderer { return kmlRenderer{} },
+	formatBadge:        func(Config) Renderer { return badgeRenderer{} },
+	formatOrg:          func(Config) Renderer { return orgRenderer{} },
+	formatTOML:         func(Config) Renderer { return tomlRenderer{} },
+	formatLaTeX:        func(Config) Renderer { return latexRenderer{} },
+	formatSQLiteExport: func(Config) Renderer { return sqliteRenderer{} },
+}
+
+func formatNames() []string {
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var resultColumns = []string{"price", "src", "dst", "start", "end", "deal", "url"}
+
+func resultRow(m Message) []string {
+	return []string{strconv.Itoa(m.Price), m.Src, m.Dst, m.Start, m.End, m.dealStatus(), m.Url}
+}
+
+type textRenderer struct{}
+
+func (textRenderer) Render(w io.Writer, messages []Message) error {
+	for _, m := range messages {
+		if _, err := fmt.Fprintln(w, FormatMessageBody(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summaryRenderer prints each result on a single line, used by --summary-only.
+type summaryRenderer struct{}
+
+func (summaryRenderer) Render(w io.Writer, messages []Message) error {
+	for _, m := range messages {
+		_, err := fmt.Fprintf(w, "Cheapest: %d %s %s → %s departing %s returning %s %s\n",
+			m.Price, m.currency(), m.Src, m.Dst, m.Start, m.End, m.Url)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Render(w io.Writer, messages []Message) error {
+	enc := json.NewEncoder(w)
+	for _, m := range messages {
+		if err := enc.Encode(ndjsonRecord{Type: recordResult, Message: m}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, messages []Message) error {
+	return json.NewEncoder(w).Encode(messages)
+}
+
+type prettyJSONRenderer struct {
+	color bool
+}
+
+func (r prettyJSONRenderer) Render(w io.Writer, messages []Message) error {
+	b, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	if r.color {
+		b = colorizeJSON(b)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+type csvRenderer struct{}
+
+func (csvRenderer) Render(w io.Writer, messages []Message) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(resultColumns); err != nil {
+		return err
+	}
+	for _, m := range messages {
+		if err := cw.Write(resultRow(m)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tsvRenderer writes the csv columns separated by tabs. Tabs and line breaks
+// inside fields are replaced by spaces as TSV has no quoting.
+type tsvRenderer struct{}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func (tsvRenderer) Render(w io.Writer, messages []Message) error {
+	if _, err := fmt.Fprintln(w, strings.Join(resultColumns, "\t")); err != nil {
+		return err
+	}
+	for _, m := range messages {
+		row := resultRow(m)
+		for i, field := range row {
+			row[i] = tsvReplacer.Replace(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tableRenderer struct {
+	colors *fareColors
+}
+
+// Render aligns the table before coloring its rows, as tabwriter would count
+// the color codes towards the width of the cells.
+func (r tableRenderer) Render(w io.Writer, messages []Message) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(resultColumns, "\t")))
+	for _, m := range messages {
+		fmt.Fprintln(tw, strings.Join(resultRow(m), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, m := range messages {
+		lines[i+1] = r.colors.colorLine(strings.TrimSuffix(lines[i+1], "\n"), m) + "\n"
+	}
+	_, err := io.WriteString(w, strings.Join(lines, ""))
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("results").Funcs(template.FuncMap{"dealStatus": Message.dealStatus}).Parse(`<table>
+<tr><th>Price</th><th>From</th><th>To</th><th>Depart</th><th>Return</th><th>Deal</th><th>Book</th></tr>
+{{range .}}<tr><td>{{.Price}}</td><td>{{.Src}}</td><td>{{.Dst}}</td><td>{{.Start}}</td><td>{{.End}}</td><td>{{dealStatus .}}</td><td><a href="{{.Url}}">Book</a></td></tr>
+{{end}}</table>
+`))
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) Render(w io.Writer, messages []Message) error {
+	return htmlTemplate.Execute(w, messages)
+}
+
+type markdownRenderer struct{}
+
+func (markdownRenderer) Render(w io.Writer, messages []Message) error {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(resultColumns, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(resultColumns)))
+	for _, m := range messages {
+		row := resultRow(m)
+		for i, field := range row {
+			row[i] = strings.ReplaceAll(field, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
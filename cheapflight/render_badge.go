@@ -0,0 +1,90 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// badgeRenderer writes a shields.io endpoint badge showing the cheapest price,
+// green when it is a deal. See https://shields.io/badges/endpoint-badge.
+type badgeRenderer struct{}
+
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+func (badgeRenderer) Render(w io.Writer, messages []Message) error {
+	return json.NewEncoder(w).Encode(newBadge(messages))
+}
+
+func newBadge(messages []Message) badge {
+	b := badge{SchemaVersion: 1, Label: "cheapest fare", Message: "no results", Color: "lightgrey"}
+	if len(messages) == 0 {
+		return b
+	}
+
+	best := cheapestMessage(messages)
+	b.Message = compactPrice(best)
+	b.Color = "blue"
+	if best.Deal {
+		b.Color = "green"
+	}
+	return b
+}
+
+// latest holds the results of the most recent check of any request, for the
+// server's /badge route, and the cheapest latest result of every route checked,
+// for its /dashboard route.
+var latest struct {
+	mu       sync.Mutex
+	messages []Message
+	routes   map[string]dashboardRoute
+}
+
+// recordCheck records the results of a check in latest, anonymized under
+// --anonymize like every other output since the routes serve them publicly.
+func recordCheck(messages []Message, cfg Config) {
+	if cfg.Anonymize {
+		messages = anonymize(messages)
+	}
+	recordLatest(messages)
+}
+
+func recordLatest(messages []Message) {
+	latest.mu.Lock()
+	defer latest.mu.Unlock()
+	latest.messages = messages
+
+	if latest.routes == nil {
+		latest.routes = make(map[string]dashboardRoute)
+	}
+	now := time.Now()
+	for _, m := range messages {
+		name := m.route
+		if name == "" {
+			name = route{Src: m.Src, Dst: m.Dst}.String()
+		}
+		if m.Direction != "" {
+			name += " (" + m.Direction + ")"
+		}
+		// Keep the cheapest of the results a check found for one route.
+		if r, ok := latest.routes[name]; ok && r.Updated.Equal(now) && r.Price <= m.Price {
+			continue
+		}
+		latest.routes[name] = dashboardRoute{Route: name, Message: m, Updated: now}
+	}
+}
+
+// RenderLatestBadge writes the shields.io badge for the results of the most
+// recent check, or a "no results" badge before the first check finishes.
+func RenderLatestBadge(w io.Writer) error {
+	latest.mu.Lock()
+	messages := latest.messages
+	latest.mu.Unlock()
+	return badgeRenderer{}.Render(w, messages)
+}
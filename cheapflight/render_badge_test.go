@@ -0,0 +1,57 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBadge(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		want     map[string]any
+	}{
+		{
+			name:     "deal",
+			messages: renderMessages,
+			want:     map[string]any{"schemaVersion": 1.0, "label": "cheapest fare", "message": "$250", "color": "green"},
+		},
+		{
+			name:     "no deal",
+			messages: []Message{{Price: 410, Currency: "EUR"}, {Price: 380, Currency: "EUR"}},
+			want:     map[string]any{"schemaVersion": 1.0, "label": "cheapest fare", "message": "€380", "color": "blue"},
+		},
+		{
+			name: "no results",
+			want: map[string]any{"schemaVersion": 1.0, "label": "cheapest fare", "message": "no results", "color": "lightgrey"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (badgeRenderer{}).Render(&buf, tt.messages); err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("badge %v, want %v", got, tt.want)
+			}
+
+			// The server's /badge route shows the latest check.
+			recordLatest(tt.messages)
+			t.Cleanup(func() { recordLatest(nil) })
+			var served bytes.Buffer
+			if err := RenderLatestBadge(&served); err != nil {
+				t.Fatal(err)
+			}
+			if served.String() != buf.String() {
+				t.Errorf("served %s, want %s", served.String(), buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// requestParamNames names the positional request arguments in combined output.
+var requestParamNames = [...]string{
+	startDateArg:        "start_date",
+	endDateArg:          "end_date",
+	durationArg:         "trip_length",
+	startArg:            "src",
+	endArg:              "dst",
+	travelerArg:         "travelers",
+	classArg:            "class",
+	tripTypeArg:         "trip_type",
+	stopArg:             "stops",
+	excludedAirlinesArg: "excluded_airlines",
+	targetArg:           "target",
+}
+
+type combinedResults struct {
+	QueriedAt time.Time            `json:"queried_at"`
+	Params    map[string]string    `json:"params"`
+	Routes    map[string][]Message `json:"routes"`
+}
+
+// combinedJSONRenderer writes one JSON document nesting the results of every
+// route under the route, along with when and with which arguments it was searched.
+type combinedJSONRenderer struct {
+	now  func() time.Time
+	args []string
+}
+
+func (r combinedJSONRenderer) Render(w io.Writer, messages []Message) error {
+	results := combinedResults{
+		QueriedAt: r.now().UTC(),
+		Params:    make(map[string]string),
+		Routes:    make(map[string][]Message),
+	}
+	for i, name := range requestParamNames {
+		if i < len(r.args) {
+			results.Params[name] = r.args[i]
+		}
+	}
+	for _, m := range messages {
+		key := m.route
+		if key == "" {
+			key = route{Src: m.Src, Dst: m.Dst}.String()
+		}
+		results.Routes[key] = append(results.Routes[key], m)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func newCombinedJSONRenderer(c Config) Renderer {
+	var args []string
+	if requestArgs := c.requestArgs(); len(requestArgs) > 1 {
+		args = requestArgs[1:]
+	}
+	return combinedJSONRenderer{now: c.now, args: args}
+}
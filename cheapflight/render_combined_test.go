@@ -0,0 +1,66 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCombinedJSON(t *testing.T) {
+	withArgs(t, "2030-03-01", "2030-03-10", "3", "SFO", "JFK", "2", "economy", "round-trip", "nonstop", "", "300", "", "")
+	cfg, err := ProcessFlags([]string{"--format=combined-json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.clock = func() time.Time { return time.Date(2030, 2, 1, 9, 30, 0, 0, time.FixedZone("PST", -8*60*60)) }
+
+	messages := []Message{
+		{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", route: "SFO → JFK"},
+		{Price: 310, Src: "OAK", Dst: "EWR", Start: "2030-03-02", End: "2030-03-05", route: "OAK → EWR"},
+		{Price: 280, Src: "SFO", Dst: "JFK", Start: "2030-03-03", End: "2030-03-06", route: "SFO → JFK"},
+	}
+	var buf bytes.Buffer
+	if err := cfg.renderer().Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		QueriedAt string                      `json:"queried_at"`
+		Params    map[string]string           `json:"params"`
+		Routes    map[string][]map[string]any `json:"routes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+
+	if doc.QueriedAt != "2030-02-01T17:30:00Z" {
+		t.Errorf("queried_at = %q, want the clock in UTC", doc.QueriedAt)
+	}
+	for name, want := range map[string]string{"start_date": "2030-03-01", "src": "SFO", "dst": "JFK", "travelers": "2", "target": "300"} {
+		if doc.Params[name] != want {
+			t.Errorf("params[%s] = %q, want %q", name, doc.Params[name], want)
+		}
+	}
+	tests := []struct {
+		route  string
+		prices []float64
+	}{
+		{route: "SFO → JFK", prices: []float64{250, 280}},
+		{route: "OAK → EWR", prices: []float64{310}},
+	}
+	if len(doc.Routes) != len(tests) {
+		t.Errorf("got routes %v, want %d", doc.Routes, len(tests))
+	}
+	for _, tt := range tests {
+		results := doc.Routes[tt.route]
+		if len(results) != len(tt.prices) {
+			t.Fatalf("route %s has %d results, want %d", tt.route, len(results), len(tt.prices))
+		}
+		for i, price := range tt.prices {
+			if results[i]["price"] != price {
+				t.Errorf("route %s result %d price = %v, want %v", tt.route, i, results[i]["price"], price)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// compactFields formats each --compact-fields field of a result.
+var compactFields = map[string]func(Message) string{
+	"route":   func(m Message) string { return m.Src + "→" + m.Dst },
+	"dates":   compactDates,
+	"price":   compactPrice,
+	"airline": func(m Message) string { return m.Airline },
+	"deal": func(m Message) string {
+		if m.Deal {
+			return "deal"
+		}
+		return ""
+	},
+	"url": func(m Message) string { return m.Url },
+}
+
+const defaultCompactFields = "route,dates,price"
+
+// currencySymbols prefix prices in common currencies, others are suffixed with their code.
+var currencySymbols = map[string]string{"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥"}
+
+func compactPrice(m Message) string {
+	if symbol, ok := currencySymbols[m.currency()]; ok {
+		return symbol + strconv.Itoa(m.Price)
+	}
+	return strconv.Itoa(m.Price) + " " + m.currency()
+}
+
+func compactDates(m Message) string {
+	if m.departure.IsZero() {
+		return m.Start
+	}
+	dates := m.departure.Format("01/02")
+	if !m.returnDate.IsZero() && dateKey(m.returnDate) != dateKey(m.departure) {
+		dates += "–" + m.returnDate.Format("01/02")
+	}
+	return dates
+}
+
+// parseCompactFields validates a --compact-fields list.
+func parseCompactFields(arg string) ([]string, error) {
+	fields := splitList(arg, ",")
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields given")
+	}
+	for _, f := range fields {
+		if _, ok := compactFields[f]; !ok {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+	}
+	return fields, nil
+}
+
+// compactRenderer prints each result on one line made of the configured
+// fields, e.g. "SFO→JFK 03/04–03/11 $312".
+type compactRenderer struct {
+	fields []string
+	colors *fareColors
+}
+
+func (r compactRenderer) Render(w io.Writer, messages []Message) error {
+	for _, m := range messages {
+		var parts []string
+		for _, f := range r.fields {
+			if part := compactFields[f](m); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if _, err := fmt.Fprintln(w, r.colors.colorLine(strings.Join(parts, " "), m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
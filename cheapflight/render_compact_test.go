@@ -0,0 +1,46 @@
+package cheapflight
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompactRenderer(t *testing.T) {
+	deal := newMessage(offerOn(day4, 312), "https://example.com/a")
+	deal.Airline = "United"
+	deal.Deal = true
+	euro := newMessage(offerOn(day1, 199), "")
+	euro.Currency = "EUR"
+	chf := newMessage(offerOn(day1, 420), "")
+	chf.Currency = "CHF"
+
+	tests := []struct {
+		flags []string
+		m     Message
+		want  string
+	}{
+		{m: deal, want: "SFO→JFK 03/04–03/07 $312\n"},
+		{flags: []string{"--compact-fields=price,route,deal,airline,url"}, m: deal, want: "$312 SFO→JFK deal United https://example.com/a\n"},
+		{flags: []string{"--compact-fields=route,deal,price"}, m: euro, want: "SFO→JFK €199\n"},
+		{m: chf, want: "SFO→JFK 03/01–03/04 420 CHF\n"},
+	}
+	for _, tt := range tests {
+		cfg, err := ProcessFlags(append([]string{"--compact", "--color=never"}, tt.flags...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := cfg.renderer().Render(&buf, []Message{tt.m}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%q rendered %q, want %q", tt.flags, buf.String(), tt.want)
+		}
+	}
+
+	for _, flags := range [][]string{{"--compact-fields=route,cabin"}, {"--format=json"}} {
+		if _, err := ProcessFlags(append([]string{"--compact"}, flags...)); err == nil {
+			t.Errorf("--compact accepted %q", flags)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// discordMaxEmbeds is the most embeds Discord accepts in one webhook message.
+	discordMaxEmbeds = 10
+
+	discordDealColor = 0x2ecc71
+)
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title  string         `json:"title"`
+	Url    string         `json:"url,omitempty"`
+	Color  int            `json:"color,omitempty"`
+	Fields []discordField `json:"fields"`
+}
+
+type discordPayload struct {
+	Content string         `json:"content"`
+	Embeds  []discordEmbed `json:"embeds"`
+}
+
+// discordRenderer produces a payload for a Discord webhook, one embed per
+// result linking to its booking page. Deals are highlighted in green.
+type discordRenderer struct{}
+
+func (discordRenderer) Render(w io.Writer, messages []Message) error {
+	payload := discordPayload{Content: fmt.Sprintf("Runway found %d flight(s)", len(messages))}
+	if len(messages) > discordMaxEmbeds {
+		payload.Content += fmt.Sprintf(", showing the first %d", discordMaxEmbeds)
+		messages = messages[:discordMaxEmbeds]
+	}
+
+	for _, m := range messages {
+		embed := discordEmbed{
+			Title: dealPrefix(m) + m.Src + " → " + m.Dst,
+			Url:   m.Url,
+			Fields: []discordField{
+				{Name: "Price", Value: strconv.Itoa(m.Price) + " " + m.currency(), Inline: true},
+				{Name: "Flying out", Value: m.Start, Inline: true},
+				{Name: "Returning", Value: m.End, Inline: true},
+			},
+		}
+		if m.Deal {
+			embed.Color = discordDealColor
+		}
+		payload.Embeds = append(payload.Embeds, embed)
+	}
+	return json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,71 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDiscordEmbeds(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (discordRenderer{}).Render(&buf, renderMessages); err != nil {
+		t.Fatal(err)
+	}
+	var payload discordPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+
+	want := []discordEmbed{
+		{
+			Title: "Deal! SFO → JFK",
+			Url:   "https://example.com/a",
+			Color: discordDealColor,
+			Fields: []discordField{
+				{Name: "Price", Value: "250 USD", Inline: true},
+				{Name: "Flying out", Value: "2030-03-01", Inline: true},
+				{Name: "Returning", Value: "2030-03-04", Inline: true},
+			},
+		},
+		{
+			Title: "OAK → EWR",
+			Url:   "https://example.com/b",
+			Fields: []discordField{
+				{Name: "Price", Value: "310 USD", Inline: true},
+				{Name: "Flying out", Value: "2030-03-02", Inline: true},
+				{Name: "Returning", Value: "2030-03-05", Inline: true},
+			},
+		},
+	}
+	if len(payload.Embeds) != len(want) {
+		t.Fatalf("got %d embeds, want %d", len(payload.Embeds), len(want))
+	}
+	for i, embed := range payload.Embeds {
+		got, _ := json.Marshal(embed)
+		wanted, _ := json.Marshal(want[i])
+		if !bytes.Equal(got, wanted) {
+			t.Errorf("embed %d = %s, want %s", i, got, wanted)
+		}
+	}
+	if payload.Content != "Runway found 2 flight(s)" {
+		t.Errorf("content = %q", payload.Content)
+	}
+}
+
+func TestDiscordEmbedLimit(t *testing.T) {
+	messages := make([]Message, discordMaxEmbeds+3)
+	for i := range messages {
+		messages[i] = renderMessages[1]
+	}
+	var buf bytes.Buffer
+	if err := (discordRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+	var payload discordPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Embeds) != discordMaxEmbeds || payload.Content != "Runway found 13 flight(s), showing the first 10" {
+		t.Errorf("got %d embeds with content %q, want the first %d noted", len(payload.Embeds), payload.Content, discordMaxEmbeds)
+	}
+}
@@ -0,0 +1,50 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// envRenderer prints the best result as shell assignments for eval.
+type envRenderer struct{}
+
+func (envRenderer) Render(w io.Writer, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	m := cheapestMessage(messages)
+
+	vars := []struct{ key, value string }{
+		{"RUNWAY_BEST_PRICE", strconv.Itoa(m.Price)},
+		{"RUNWAY_BEST_URL", m.Url},
+		{"RUNWAY_BEST_START", m.Start},
+		{"RUNWAY_BEST_END", m.End},
+		{"RUNWAY_BEST_SRC", m.Src},
+		{"RUNWAY_BEST_DST", m.Dst},
+		{"RUNWAY_BEST_AIRLINE", m.Airline},
+		{"RUNWAY_BEST_DEAL", strconv.FormatBool(m.Deal)},
+	}
+	for _, v := range vars {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", v.key, shellQuote(v.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote wraps s in single quotes, which POSIX shells take literally.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func cheapestMessage(messages []Message) Message {
+	best := messages[0]
+	for _, m := range messages[1:] {
+		if m.Price < best.Price {
+			best = m
+		}
+	}
+	return best
+}
@@ -0,0 +1,53 @@
+package cheapflight
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestEnvRendererQuoting(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to evaluate the output with")
+	}
+	tests := []struct {
+		name    string
+		airline string
+		url     string
+	}{
+		{name: "plain", airline: "Delta", url: "https://example.com/book"},
+		{name: "query string", airline: "Delta", url: "https://example.com/book?a=1&b=$HOME;rm -rf /"},
+		{name: "quotes", airline: `Cathay's "Pacific"`, url: "https://example.com/it's"},
+		{name: "backticks and newline", airline: "`id`\nnext", url: "$(id)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := []Message{
+				{Price: 400, Src: "SFO", Dst: "JFK"},
+				{Price: 250, Src: "SFO", Dst: "JFK", Airline: tt.airline, Url: tt.url},
+			}
+			var buf bytes.Buffer
+			if err := (envRenderer{}).Render(&buf, messages); err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "'\n") {
+				if _, value, _ := strings.Cut(line, "="); !strings.HasPrefix(value, "'") {
+					t.Errorf("value is not quoted: %q", line)
+				}
+			}
+
+			script := buf.String() + `printf '%s\0' "$RUNWAY_BEST_PRICE" "$RUNWAY_BEST_AIRLINE" "$RUNWAY_BEST_URL"`
+			out, err := exec.Command(sh, "-c", script).Output()
+			if err != nil {
+				t.Fatalf("evaluating %q: %v", buf.String(), err)
+			}
+			got := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+			want := []string{"250", tt.airline, tt.url}
+			if strings.Join(got, "|") != strings.Join(want, "|") {
+				t.Errorf("evaluated to %q, want %q", got, want)
+			}
+		})
+	}
+}
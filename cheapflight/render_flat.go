@@ -0,0 +1,45 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// flatJSONRenderer prints the cheapest result as a single JSON object with
+// only scalar values, which tools such as Apple Shortcuts read as a plain
+// dictionary. The price range and converted prices become top level keys.
+type flatJSONRenderer struct{}
+
+func (flatJSONRenderer) Render(w io.Writer, messages []Message) error {
+	obj := map[string]any{}
+	if len(messages) > 0 {
+		obj = flattenMessage(cheapestMessage(messages))
+	}
+	return json.NewEncoder(w).Encode(obj)
+}
+
+func flattenMessage(m Message) map[string]any {
+	obj := map[string]any{
+		"price":    m.Price,
+		"currency": m.currency(),
+		"url":      m.Url,
+		"start":    m.Start,
+		"end":      m.End,
+		"src":      m.Src,
+		"dst":      m.Dst,
+		"airline":  m.Airline,
+		"deal":     m.Deal,
+	}
+	if m.PriceRange != nil {
+		obj["price_low"] = m.PriceRange.Low
+		obj["price_high"] = m.PriceRange.High
+	}
+	if m.Travelers > 0 {
+		obj["travelers"] = m.Travelers
+		obj["per_person_price"] = m.PerPersonPrice
+	}
+	for currency, price := range m.Prices {
+		obj["price_"+currency] = price
+	}
+	return obj
+}
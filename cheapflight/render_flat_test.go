@@ -0,0 +1,52 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestFlatJSON(t *testing.T) {
+	cheap := Message{Price: 250, Src: "OAK", Dst: "EWR", Start: "2030-03-02", End: "2030-03-05", Airline: "United", Url: "https://example.com/b",
+		PriceRange: &flights.PriceRange{Low: 280, High: 400}, Deal: true, Prices: map[string]float64{"EUR": 230.5}}
+	tests := []struct {
+		name     string
+		messages []Message
+		want     map[string]any
+	}{
+		{
+			name:     "cheapest result",
+			messages: []Message{renderMessages[1], cheap},
+			want: map[string]any{
+				"price": 250.0, "currency": "USD", "url": "https://example.com/b", "start": "2030-03-02", "end": "2030-03-05",
+				"src": "OAK", "dst": "EWR", "airline": "United", "deal": true,
+				"price_low": 280.0, "price_high": 400.0, "price_EUR": 230.5,
+			},
+		},
+		{name: "no results", want: map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (flatJSONRenderer{}).Render(&buf, tt.messages); err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("output is not a JSON object: %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for key, value := range got {
+				switch value.(type) {
+				case map[string]any, []any:
+					t.Errorf("%s is not a plain value: %v", key, value)
+				}
+			}
+		})
+	}
+}
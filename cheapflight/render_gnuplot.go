@@ -0,0 +1,26 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// gnuplotRenderer writes a whitespace separated datafile of departure date
+// and price, ordered by date, for plotting with gnuplot or matplotlib.
+type gnuplotRenderer struct{}
+
+func (gnuplotRenderer) Render(w io.Writer, messages []Message) error {
+	sorted := append([]Message(nil), messages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].departure.Before(sorted[j].departure) })
+
+	if _, err := fmt.Fprintln(w, "# date price"); err != nil {
+		return err
+	}
+	for _, m := range sorted {
+		if _, err := fmt.Fprintf(w, "%s %d\n", dateKey(m.departure), m.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
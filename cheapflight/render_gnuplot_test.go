@@ -0,0 +1,42 @@
+package cheapflight
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGnuplotRenderer(t *testing.T) {
+	messages := []Message{
+		newMessage(offerOn(day3, 310), ""),
+		newMessage(offerOn(day1, 250), ""),
+		newMessage(offerOn(day2, 275), ""),
+	}
+	var buf bytes.Buffer
+	if err := (gnuplotRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != "# date price" {
+		t.Errorf("header = %q, want a comment naming the columns", lines[0])
+	}
+	want := []string{"2030-03-01 250", "2030-03-02 275", "2030-03-03 310"}
+	if got := lines[1:]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("rows %q, want %q ordered by date", got, want)
+	}
+	for _, row := range lines[1:] {
+		fields := strings.Fields(row)
+		if len(fields) != 2 {
+			t.Fatalf("row %q has %d columns, want 2", row, len(fields))
+		}
+		if _, err := time.Parse(time.DateOnly, fields[0]); err != nil {
+			t.Errorf("row %q: date column: %v", row, err)
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			t.Errorf("row %q: price column: %v", row, err)
+		}
+	}
+}
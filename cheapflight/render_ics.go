@@ -0,0 +1,74 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icsTimeFormat = "20060102T150405Z"
+	icsDateFormat = "20060102"
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// icsBuilder accumulates the lines of an iCalendar document.
+type icsBuilder struct {
+	lines []string
+}
+
+func (b *icsBuilder) add(format string, a ...interface{}) {
+	b.lines = append(b.lines, fmt.Sprintf(format, a...))
+}
+
+func (b *icsBuilder) event(uid string, stamp time.Time, start, end string, summary, description string) {
+	b.add("BEGIN:VEVENT")
+	b.add("UID:%s", uid)
+	b.add("DTSTAMP:%s", stamp.UTC().Format(icsTimeFormat))
+	b.add("%s", start)
+	b.add("%s", end)
+	b.add("SUMMARY:%s", icsEscaper.Replace(summary))
+	if description != "" {
+		b.add("DESCRIPTION:%s", icsEscaper.Replace(description))
+	}
+	b.add("END:VEVENT")
+}
+
+func (b *icsBuilder) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, strings.Join(b.lines, "\r\n")+"\r\n")
+	return int64(n), err
+}
+
+// icsRenderer emits a calendar event for the outbound flight of every result,
+// plus an all day event on the return date of round trips.
+type icsRenderer struct{}
+
+func (icsRenderer) Render(w io.Writer, messages []Message) error {
+	b := &icsBuilder{}
+	b.add("BEGIN:VCALENDAR")
+	b.add("VERSION:2.0")
+	b.add("PRODID:-//runway//flights//EN")
+
+	now := time.Now()
+	for _, m := range messages {
+		fp := fingerprint(m)
+		description := fmt.Sprintf("%d %s %s", m.Price, m.currency(), m.Url)
+		b.event(fp+"-out@runway", now,
+			"DTSTART:"+m.departure.UTC().Format(icsTimeFormat),
+			"DTEND:"+m.arrival.UTC().Format(icsTimeFormat),
+			fmt.Sprintf("Flight %s → %s", m.Src, m.Dst), description)
+
+		if !m.returnDate.IsZero() {
+			b.event(fp+"-return@runway", now,
+				"DTSTART;VALUE=DATE:"+m.returnDate.Format(icsDateFormat),
+				"DTEND;VALUE=DATE:"+m.returnDate.AddDate(0, 0, 1).Format(icsDateFormat),
+				fmt.Sprintf("Return flight %s → %s", m.Dst, m.Src), description)
+		}
+	}
+
+	b.add("END:VCALENDAR")
+	_, err := b.WriteTo(w)
+	return err
+}
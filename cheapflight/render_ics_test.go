@@ -0,0 +1,89 @@
+package cheapflight
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icsEvents parses the properties of each VEVENT in an iCalendar document.
+func icsEvents(t *testing.T, doc string) []map[string]string {
+	t.Helper()
+	if !strings.HasSuffix(doc, "\r\n") {
+		t.Fatalf("document does not end in CRLF: %q", doc)
+	}
+	var events []map[string]string
+	var event map[string]string
+	for _, line := range strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n") {
+		switch line {
+		case "BEGIN:VEVENT":
+			event = make(map[string]string)
+		case "END:VEVENT":
+			events = append(events, event)
+			event = nil
+		default:
+			if event == nil {
+				continue
+			}
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				t.Fatalf("malformed line %q", line)
+			}
+			event[name] = value
+		}
+	}
+	return events
+}
+
+func TestICSEvents(t *testing.T) {
+	departure := time.Date(2030, 3, 1, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		message Message
+		want    []map[string]string
+	}{
+		{
+			name:    "one way",
+			message: Message{Src: "SFO", Dst: "JFK", departure: departure, arrival: departure.Add(5 * time.Hour)},
+			want: []map[string]string{
+				{"DTSTART": "20300301T083000Z", "DTEND": "20300301T133000Z", "SUMMARY": "Flight SFO → JFK"},
+			},
+		},
+		{
+			name:    "round trip",
+			message: Message{Src: "SFO", Dst: "JFK", departure: departure, arrival: departure.Add(5 * time.Hour), returnDate: day4},
+			want: []map[string]string{
+				{"DTSTART": "20300301T083000Z", "DTEND": "20300301T133000Z", "SUMMARY": "Flight SFO → JFK"},
+				{"DTSTART;VALUE=DATE": "20300304", "DTEND;VALUE=DATE": "20300305", "SUMMARY": "Return flight JFK → SFO"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (icsRenderer{}).Render(&buf, []Message{tt.message}); err != nil {
+				t.Fatal(err)
+			}
+			doc := buf.String()
+			if !strings.HasPrefix(doc, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(doc, "END:VCALENDAR\r\n") {
+				t.Errorf("not a calendar:\n%s", doc)
+			}
+
+			events := icsEvents(t, doc)
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := make(map[string]string)
+				for k := range want {
+					got[k] = events[i][k]
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("event %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
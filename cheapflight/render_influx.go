@@ -0,0 +1,31 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// influxRenderer writes one InfluxDB line protocol point per result:
+// fare,src=SFO,dst=JFK,date=2024-03-04 price=312i <unix nanoseconds>
+type influxRenderer struct {
+	now func() time.Time
+}
+
+func (r influxRenderer) Render(w io.Writer, messages []Message) error {
+	ts := r.now().UnixNano()
+	for _, m := range messages {
+		_, err := fmt.Fprintf(w, "fare,src=%s,dst=%s,date=%s price=%di %d\n",
+			influxTagEscaper.Replace(m.Src),
+			influxTagEscaper.Replace(m.Dst),
+			influxTagEscaper.Replace(dateKey(m.departure)),
+			m.Price, ts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
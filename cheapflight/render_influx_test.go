@@ -0,0 +1,39 @@
+package cheapflight
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestInfluxLines(t *testing.T) {
+	now := time.Unix(1900000000, 0)
+	tests := []struct {
+		name    string
+		message Message
+		want    string
+	}{
+		{
+			name:    "airports",
+			message: Message{Src: "SFO", Dst: "JFK", Price: 312, departure: day1},
+			want:    "fare,src=SFO,dst=JFK,date=2030-03-01 price=312i 1900000000000000000\n",
+		},
+		{
+			name:    "escaped tags",
+			message: Message{Src: "san francisco,ca", Dst: "a=b", Price: 99, departure: day2},
+			want:    `fare,src=san\ francisco\,ca,dst=a\=b,date=2030-03-02 price=99i 1900000000000000000` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := influxRenderer{now: func() time.Time { return now }}
+			if err := r.Render(&buf, []Message{tt.message}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got  %q\nwant %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
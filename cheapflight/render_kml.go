@@ -0,0 +1,91 @@
+package cheapflight
+
+import (
+	_ "embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//go:embed data/airports.json
+var airportsJSON []byte
+
+// airportCoordinates maps the IATA codes of major airports to their latitude
+// and longitude, for placing results on a map.
+var airportCoordinates = func() map[string][2]float64 {
+	coordinates := make(map[string][2]float64)
+	if err := json.Unmarshal(airportsJSON, &coordinates); err != nil {
+		panic(err)
+	}
+	return coordinates
+}()
+
+// kmlRenderer writes a KML document with a placemark for every airport and a
+// line from origin to destination for every result. Airports without known
+// coordinates are left out, along with the lines touching them.
+type kmlRenderer struct{}
+
+type kmlDocument struct {
+	XMLName    xml.Name       `xml:"kml"`
+	Namespace  string         `xml:"xmlns,attr"`
+	Name       string         `xml:"Document>name"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	Point       *kmlCoordinate `xml:"Point,omitempty"`
+	LineString  *kmlCoordinate `xml:"LineString,omitempty"`
+}
+
+type kmlCoordinate struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+func (kmlRenderer) Render(w io.Writer, messages []Message) error {
+	doc := kmlDocument{Namespace: "http://www.opengis.net/kml/2.2", Name: "Runway results"}
+
+	seen := make(map[string]bool)
+	for _, m := range messages {
+		for _, airport := range []string{m.Src, m.Dst} {
+			if _, ok := airportCoordinates[airport]; !ok || seen[airport] {
+				continue
+			}
+			seen[airport] = true
+			doc.Placemarks = append(doc.Placemarks, kmlPlacemark{
+				Name:  airport,
+				Point: &kmlCoordinate{Coordinates: kmlCoordinates(airport)},
+			})
+		}
+	}
+	for _, m := range messages {
+		if !seen[m.Src] || !seen[m.Dst] {
+			continue
+		}
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{
+			Name:        fmt.Sprintf("%s → %s %d %s", m.Src, m.Dst, m.Price, m.currency()),
+			Description: strings.TrimSpace(m.Start + " " + m.Url),
+			LineString:  &kmlCoordinate{Coordinates: kmlCoordinates(m.Src) + " " + kmlCoordinates(m.Dst)},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// kmlCoordinates formats an airport's position as KML's longitude,latitude.
+func kmlCoordinates(airport string) string {
+	c := airportCoordinates[airport]
+	return fmt.Sprintf("%g,%g", c[1], c[0])
+}
@@ -0,0 +1,51 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestKMLRenderer(t *testing.T) {
+	messages := []Message{
+		{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01"},
+		{Price: 310, Src: "SFO", Dst: "XXX", Start: "2030-03-02"},
+	}
+	var buf bytes.Buffer
+	if err := (kmlRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+	var doc kmlDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not XML: %v\n%s", err, buf.String())
+	}
+
+	sfo, jfk := kmlCoordinates("SFO"), kmlCoordinates("JFK")
+	tests := []struct {
+		name       string
+		point      string
+		lineString string
+	}{
+		{name: "SFO", point: sfo},
+		{name: "JFK", point: jfk},
+		{name: "SFO → JFK 250 USD", lineString: sfo + " " + jfk},
+	}
+	if len(doc.Placemarks) != len(tests) {
+		t.Fatalf("got %d placemarks, want %d leaving out the unknown airport:\n%s", len(doc.Placemarks), len(tests), buf.String())
+	}
+	for i, tt := range tests {
+		p := doc.Placemarks[i]
+		if p.Name != tt.name {
+			t.Errorf("placemark %d name = %q, want %q", i, p.Name, tt.name)
+		}
+		if tt.point != "" && (p.Point == nil || p.Point.Coordinates != tt.point) {
+			t.Errorf("%s point = %+v, want %s", tt.name, p.Point, tt.point)
+		}
+		if tt.lineString != "" && (p.LineString == nil || p.LineString.Coordinates != tt.lineString) {
+			t.Errorf("%s line = %+v, want %s", tt.name, p.LineString, tt.lineString)
+		}
+	}
+	if sfo != "-122.379,37.6213" {
+		t.Errorf("SFO at %s, want longitude before latitude", sfo)
+	}
+}
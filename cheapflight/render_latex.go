@@ -0,0 +1,42 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// latexRenderer writes a LaTeX table environment of the results, ready to
+// paste into a document.
+type latexRenderer struct{}
+
+// latexEscaper escapes the characters LaTeX treats specially in text.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"{", `\{`,
+	"}", `\}`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+func (latexRenderer) Render(w io.Writer, messages []Message) error {
+	var b strings.Builder
+	b.WriteString("\\begin{table}[ht]\n\\centering\n\\begin{tabular}{rlllll}\n\\hline\n")
+	b.WriteString("Price & From & To & Depart & Return & Deal \\\\\n\\hline\n")
+	for _, m := range messages {
+		fields := []string{compactPrice(m), m.Src, m.Dst, m.Start, m.End, m.dealStatus()}
+		for i, field := range fields {
+			fields[i] = latexEscaper.Replace(field)
+		}
+		fmt.Fprintf(&b, "%s \\\\\n", strings.Join(fields, " & "))
+	}
+	b.WriteString("\\hline\n\\end{tabular}\n\\caption{Flight offers}\n\\end{table}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
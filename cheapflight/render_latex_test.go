@@ -0,0 +1,42 @@
+package cheapflight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLaTeXEscaper(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "$250 (10% off)", want: `\$250 (10\% off)`},
+		{in: "A&B_C", want: `A\&B\_C`},
+		{in: `#{x}~^\`, want: `\#\{x\}\textasciitilde{}\textasciicircum{}\textbackslash{}`},
+		{in: "SFO", want: "SFO"},
+	}
+	for _, tt := range tests {
+		if got := latexEscaper.Replace(tt.in); got != tt.want {
+			t.Errorf("latexEscaper.Replace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLaTeXRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	messages := []Message{{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Deal: true}}
+	if err := (latexRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\\begin{table}[ht]\n\\centering\n\\begin{tabular}{rlllll}\n\\hline\n" +
+		"Price & From & To & Depart & Return & Deal \\\\\n\\hline\n" +
+		"\\$250 & SFO & JFK & 2030-03-01 & 2030-03-04 & true \\\\\n" +
+		"\\hline\n\\end{tabular}\n\\caption{Flight offers}\n\\end{table}\n"
+	if buf.String() != want {
+		t.Errorf("rendered\n%s\nwant\n%s", buf.String(), want)
+	}
+	if strings.Count(buf.String(), `\begin{`) != strings.Count(buf.String(), `\end{`) {
+		t.Errorf("unbalanced environments:\n%s", buf.String())
+	}
+}
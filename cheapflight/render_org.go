@@ -0,0 +1,61 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// orgRenderer writes an aligned org-mode table, with each price linking to
+// its booking page.
+type orgRenderer struct{}
+
+// orgEscaper keeps field text from ending a cell or an org link early.
+var orgEscaper = strings.NewReplacer("|", `\vert{}`, "[", "%5B", "]", "%5D")
+
+func (orgRenderer) Render(w io.Writer, messages []Message) error {
+	rows := [][]string{{"price", "src", "dst", "start", "end", "deal"}}
+	for _, m := range messages {
+		price := strconv.Itoa(m.Price)
+		if m.Url != "" {
+			price = fmt.Sprintf("[[%s][%d]]", orgEscaper.Replace(m.Url), m.Price)
+		}
+		rows = append(rows, []string{
+			price,
+			orgEscaper.Replace(m.Src),
+			orgEscaper.Replace(m.Dst),
+			orgEscaper.Replace(m.Start),
+			orgEscaper.Replace(m.End),
+			m.dealStatus(),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, field := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(field))
+		}
+	}
+
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, field := range row {
+			cells[j] = field + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(field))
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+			return err
+		}
+		if i == 0 {
+			rules := make([]string, len(widths))
+			for j, width := range widths {
+				rules[j] = strings.Repeat("-", width+2)
+			}
+			if _, err := fmt.Fprintf(w, "|%s|\n", strings.Join(rules, "+")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package cheapflight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOrgRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (orgRenderer{}).Render(&buf, renderMessages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "" +
+		"| price                          | src | dst | start      | end        | deal    |\n" +
+		"|--------------------------------+-----+-----+------------+------------+---------|\n" +
+		"| [[https://example.com/a][250]] | SFO | JFK | 2030-03-01 | 2030-03-04 | true    |\n" +
+		"| [[https://example.com/b][310]] | OAK | EWR | 2030-03-02 | 2030-03-05 | unknown |\n"
+	if buf.String() != want {
+		t.Errorf("rendered\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestOrgRendererEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Message
+		want string
+	}{
+		{name: "no url", m: Message{Price: 250}, want: "| 250   |"},
+		{name: "brackets in url", m: Message{Price: 250, Url: "https://example.com/?q=[a]"}, want: "[[https://example.com/?q=%5Ba%5D][250]]"},
+		{name: "bar in field", m: Message{Price: 250, Src: "S|FO"}, want: `S\vert{}FO`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (orgRenderer{}).Render(&buf, []Message{tt.m}); err != nil {
+				t.Fatal(err)
+			}
+			row := strings.Split(buf.String(), "\n")[2]
+			if !strings.Contains(row, tt.want) {
+				t.Errorf("row %q does not contain %q", row, tt.want)
+			}
+			if cells := strings.Count(row, " | ") + 1; cells != 6 {
+				t.Errorf("row has %d cells, want 6: %q", cells, row)
+			}
+		})
+	}
+}
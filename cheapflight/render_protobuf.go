@@ -0,0 +1,41 @@
+package cheapflight
+
+import (
+	"io"
+
+	"github.com/ajhingran/runway/proto/runwayv1"
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufRenderer writes the results as one runway.v1.SearchResults message,
+// defined in proto/runway.proto.
+type protobufRenderer struct{}
+
+func (protobufRenderer) Render(w io.Writer, messages []Message) error {
+	b, err := proto.Marshal(protobufResults(messages))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// protobufResults converts messages to the runway.v1.SearchResults written by
+// --format protobuf and returned by the gRPC Search call.
+func protobufResults(messages []Message) *runwayv1.SearchResults {
+	results := &runwayv1.SearchResults{}
+	for _, m := range messages {
+		results.Results = append(results.Results, &runwayv1.Result{
+			Price:    int64(m.Price),
+			Currency: m.currency(),
+			Src:      m.Src,
+			Dst:      m.Dst,
+			Start:    m.Start,
+			End:      m.End,
+			Airline:  m.Airline,
+			Url:      m.Url,
+			Deal:     m.Deal,
+		})
+	}
+	return results
+}
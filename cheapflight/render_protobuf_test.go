@@ -0,0 +1,63 @@
+package cheapflight
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeFields reads the top-level fields of a protobuf message, keeping
+// varints as uint64 and length-delimited fields as strings.
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+	fields := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("bad tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatalf("bad varint: %v", protowire.ParseError(n))
+			}
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatalf("bad bytes: %v", protowire.ParseError(n))
+			}
+			fields[num] = append(fields[num], string(v))
+			b = b[n:]
+		default:
+			t.Fatalf("field %d has unexpected wire type %d", num, typ)
+		}
+	}
+	return fields
+}
+
+func TestProtobufRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (protobufRenderer{}).Render(&buf, renderMessages); err != nil {
+		t.Fatal(err)
+	}
+
+	results := decodeFields(t, buf.Bytes())[1]
+	if len(results) != len(renderMessages) {
+		t.Fatalf("got %d results, want %d", len(results), len(renderMessages))
+	}
+	want := []map[protowire.Number][]any{
+		{1: {uint64(250)}, 2: {"USD"}, 3: {"SFO"}, 4: {"JFK"}, 5: {"2030-03-01"}, 6: {"2030-03-04"}, 8: {"https://example.com/a"}, 9: {uint64(1)}},
+		{1: {uint64(310)}, 2: {"USD"}, 3: {"OAK"}, 4: {"EWR"}, 5: {"2030-03-02"}, 6: {"2030-03-05"}, 8: {"https://example.com/b"}},
+	}
+	for i, result := range results {
+		if got := decodeFields(t, []byte(result.(string))); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("result %d decoded to %v, want %v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cheapflight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrMatrix encodes url as a QR code, true marking dark modules. The matrix
+// includes the quiet zone border scanners need.
+func qrMatrix(url string) ([][]bool, error) {
+	code, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return code.Bitmap(), nil
+}
+
+// qrString draws the matrix with Unicode half blocks, two rows per line, dark
+// modules in the terminal's foreground color.
+func qrString(matrix [][]bool) string {
+	var b strings.Builder
+	for y := 0; y < len(matrix); y += 2 {
+		for x := range matrix[y] {
+			top := matrix[y][x]
+			bottom := y+1 < len(matrix) && matrix[y+1][x]
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// qrRenderer prints the booking URL of the cheapest result as a QR code.
+type qrRenderer struct{}
+
+func (qrRenderer) Render(w io.Writer, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	m := cheapestMessage(messages)
+	if m.Url == "" {
+		return fmt.Errorf("no booking URL to encode for %s → %s", m.Src, m.Dst)
+	}
+
+	matrix, err := qrMatrix(m.Url)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s → %s %d %s\n%s", m.Src, m.Dst, m.Price, m.currency(), qrString(matrix))
+	return err
+}
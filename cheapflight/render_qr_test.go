@@ -0,0 +1,78 @@
+package cheapflight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readQRString turns the half blocks drawn by qrString back into a matrix of
+// the given height.
+func readQRString(s string, height int) [][]bool {
+	matrix := make([][]bool, 0, height)
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		var top, bottom []bool
+		for _, r := range line {
+			top = append(top, r == '█' || r == '▀')
+			bottom = append(bottom, r == '█' || r == '▄')
+		}
+		matrix = append(matrix, top)
+		if len(matrix) < height {
+			matrix = append(matrix, bottom)
+		}
+	}
+	return matrix
+}
+
+// hasFinder reports whether a 7x7 finder pattern starts at x, y: a dark ring
+// around a light ring around a dark 3x3 square.
+func hasFinder(m [][]bool, x, y int) bool {
+	for dy := 0; dy < 7; dy++ {
+		for dx := 0; dx < 7; dx++ {
+			ring := min(min(dx, dy), min(6-dx, 6-dy))
+			if m[y+dy][x+dx] != (ring != 1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestQRMatrix(t *testing.T) {
+	urls := []string{
+		"https://www.google.com/travel/flights/search?tfs=CBwQAhoeEgoyMDMwLTAzLTAxagcIARIDU0ZPcgcIARIDSkZL",
+		"https://example.com/b",
+	}
+	var matrices [][][]bool
+	for _, url := range urls {
+		matrix, err := qrMatrix(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matrix) == 0 {
+			t.Fatalf("empty matrix for %s", url)
+		}
+		for _, row := range matrix {
+			if len(row) != len(matrix) {
+				t.Fatalf("matrix is not square: %d rows, a row of %d", len(matrix), len(row))
+			}
+		}
+
+		// The quiet zone is 4 modules, the finder patterns sit in three corners inside it.
+		const quiet = 4
+		size := len(matrix) - 2*quiet
+		for _, corner := range [][2]int{{quiet, quiet}, {quiet + size - 7, quiet}, {quiet, quiet + size - 7}} {
+			if !hasFinder(matrix, corner[0], corner[1]) {
+				t.Errorf("no finder pattern at %v for %s", corner, url)
+			}
+		}
+
+		if drawn := readQRString(qrString(matrix), len(matrix)); !reflect.DeepEqual(drawn, matrix) {
+			t.Errorf("drawing of the QR code for %s does not read back to its matrix", url)
+		}
+		matrices = append(matrices, matrix)
+	}
+	if reflect.DeepEqual(matrices[0], matrices[1]) {
+		t.Error("different URLs encode to the same matrix")
+	}
+}
@@ -0,0 +1,58 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	Url  string    `json:"url"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackRenderer produces a Block Kit payload for a Slack incoming webhook,
+// one section and booking button per result.
+type slackRenderer struct{}
+
+func (slackRenderer) Render(w io.Writer, messages []Message) error {
+	payload := slackPayload{Text: fmt.Sprintf("Runway found %d flight(s)", len(messages))}
+	for _, m := range messages {
+		payload.Blocks = append(payload.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*%s → %s*\n%sPrice: %d %s\nFlying out on %s\nReturning on %s",
+					m.Src, m.Dst, dealPrefix(m), m.Price, m.currency(), m.Start, m.End),
+			},
+		})
+		if m.Url != "" {
+			payload.Blocks = append(payload.Blocks, slackBlock{
+				Type: "actions",
+				Elements: []slackElement{{
+					Type: "button",
+					Text: slackText{Type: "plain_text", Text: "Book"},
+					Url:  m.Url,
+				}},
+			})
+		}
+	}
+	return json.NewEncoder(w).Encode(payload)
+}
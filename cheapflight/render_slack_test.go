@@ -0,0 +1,41 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSlackBlocks(t *testing.T) {
+	messages := []Message{
+		{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Url: "https://example.com/book"},
+		{Price: 310, Src: "OAK", Dst: "EWR", Start: "2030-03-02", End: "2030-03-05"},
+	}
+	var buf bytes.Buffer
+	if err := (slackRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+	var payload slackPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+
+	wantTypes := []string{"section", "actions", "section"}
+	if len(payload.Blocks) != len(wantTypes) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(payload.Blocks), len(wantTypes), payload.Blocks)
+	}
+	for i, block := range payload.Blocks {
+		if block.Type != wantTypes[i] {
+			t.Errorf("block %d type = %q, want %q", i, block.Type, wantTypes[i])
+		}
+	}
+	for _, i := range []int{0, 2} {
+		if text := payload.Blocks[i].Text; text == nil || text.Type != "mrkdwn" {
+			t.Errorf("section %d text = %+v, want mrkdwn", i, text)
+		}
+	}
+	buttons := payload.Blocks[1].Elements
+	if len(buttons) != 1 || buttons[0].Type != "button" || buttons[0].Url != "https://example.com/book" {
+		t.Errorf("actions = %+v, want one button linking to the booking URL", buttons)
+	}
+}
@@ -0,0 +1,93 @@
+package cheapflight
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE results (
+	id             INTEGER PRIMARY KEY,
+	price          INTEGER NOT NULL,
+	currency       TEXT,
+	src            TEXT NOT NULL,
+	dst            TEXT NOT NULL,
+	start          TEXT,
+	end            TEXT,
+	airline        TEXT,
+	deal           INTEGER NOT NULL,
+	price_low      REAL,
+	price_high     REAL,
+	price_per_mile REAL,
+	url            TEXT
+)`
+
+// sqliteRenderer writes the results as a standalone SQLite database with one
+// row per result in a results table. The database is built in a temporary
+// file and then copied to the output, so every run writes a fresh file.
+type sqliteRenderer struct{}
+
+func (sqliteRenderer) Render(w io.Writer, messages []Message) error {
+	dir, err := os.MkdirTemp("", "runway-sqlite")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "results.sqlite")
+	if err := writeSQLite(path, messages); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func writeSQLite(path string, messages []Message) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	insert, err := tx.Prepare(`INSERT INTO results
+		(price, currency, src, dst, start, end, airline, deal, price_low, price_high, price_per_mile, url)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, m := range messages {
+		var low, high sql.NullFloat64
+		if m.PriceRange != nil {
+			low = sql.NullFloat64{Float64: m.PriceRange.Low, Valid: true}
+			high = sql.NullFloat64{Float64: m.PriceRange.High, Valid: true}
+		}
+		perMile := sql.NullFloat64{Float64: m.PricePerMile, Valid: m.PricePerMile > 0}
+		if _, err := insert.Exec(m.Price, m.currency(), m.Src, m.Dst, m.Start, m.End, m.Airline, m.Deal, low, high, perMile, m.Url); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return db.Close()
+}
@@ -0,0 +1,78 @@
+package cheapflight
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestSQLiteRenderer(t *testing.T) {
+	messages := []Message{
+		{Price: 250, Currency: "USD", Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Airline: "United", Deal: true,
+			PriceRange: &flights.PriceRange{Low: 300, High: 400}, PricePerMile: 0.05, Url: "https://example.com/a"},
+		{Price: 310, Src: "OAK", Dst: "EWR", Start: "2030-03-02", End: "2030-03-05", Url: "https://example.com/b"},
+	}
+	var buf bytes.Buffer
+	if err := (sqliteRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.sqlite")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT price, currency, src, dst, start, end, airline, deal, price_low, price_high, price_per_mile, url FROM results ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type row struct {
+		price               int
+		currency, src, dst  string
+		start, end, airline string
+		deal                bool
+		low, high, perMile  sql.NullFloat64
+		url                 string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.price, &r.currency, &r.src, &r.dst, &r.start, &r.end, &r.airline, &r.deal, &r.low, &r.high, &r.perMile, &r.url); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []row{
+		{250, "USD", "SFO", "JFK", "2030-03-01", "2030-03-04", "United", true,
+			sql.NullFloat64{Float64: 300, Valid: true}, sql.NullFloat64{Float64: 400, Valid: true}, sql.NullFloat64{Float64: 0.05, Valid: true}, "https://example.com/a"},
+		// The currency defaults to USD, and a missing range or price per mile is NULL.
+		{310, "USD", "OAK", "EWR", "2030-03-02", "2030-03-05", "", false, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}, "https://example.com/b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("queried\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestSQLiteExportNeedsOutputFile(t *testing.T) {
+	if _, err := ProcessFlags([]string{"--format=sqlite-export"}); err == nil {
+		t.Error("--format sqlite-export was accepted without --output-file")
+	}
+	if _, err := ProcessFlags([]string{"--format=sqlite-export", "--output-file=" + filepath.Join(t.TempDir(), "out.sqlite")}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package cheapflight
+
+import (
+	"io"
+	"text/template"
+)
+
+// templateData is the value each --output-template is executed with.
+type templateData struct {
+	Price     int
+	Currency  string
+	StartDate string
+	EndDate   string
+	Src       string
+	Dst       string
+	Airline   string
+	Deal      bool
+	URL       string
+}
+
+func newTemplateData(m Message) templateData {
+	return templateData{
+		Price:     m.Price,
+		Currency:  m.currency(),
+		StartDate: m.Start,
+		EndDate:   m.End,
+		Src:       m.Src,
+		Dst:       m.Dst,
+		Airline:   m.Airline,
+		Deal:      m.Deal,
+		URL:       m.Url,
+	}
+}
+
+// parseOutputTemplate parses an --output-template, executing it once against an
+// empty result so that unknown fields fail at startup rather than on the first result.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, templateData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// templateRenderer executes --output-template once per result.
+type templateRenderer struct {
+	tmpl *template.Template
+}
+
+func (r templateRenderer) Render(w io.Writer, messages []Message) error {
+	for _, m := range messages {
+		if err := r.tmpl.Execute(w, newTemplateData(m)); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
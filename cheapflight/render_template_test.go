@@ -0,0 +1,46 @@
+package cheapflight
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "fields",
+			template: "{{.Src}}-{{.Dst}} {{.Price}} {{.Currency}} {{.StartDate}}/{{.EndDate}} {{.URL}}",
+			want:     "SFO-JFK 250 USD 2030-03-01/2030-03-04 https://example.com/a\nOAK-EWR 310 USD 2030-03-02/2030-03-05 https://example.com/b\n",
+		},
+		{
+			name:     "conditionals",
+			template: "{{if .Deal}}DEAL {{end}}{{.Price}}",
+			want:     "DEAL 250\n310\n",
+		},
+		{name: "unknown field", template: "{{.Cost}}", wantErr: true},
+		{name: "syntax error", template: "{{.Price", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--output-template=" + tt.template})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessFlags error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var buf bytes.Buffer
+			if err := cfg.renderer().Render(&buf, renderMessages); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("rendered %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package cheapflight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var renderMessages = []Message{
+	{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Deal: true, Url: "https://example.com/a"},
+	{Price: 310, Src: "OAK", Dst: "EWR", Start: "2030-03-02", End: "2030-03-05", DealUnknown: true, Url: "https://example.com/b"},
+}
+
+func TestRenderers(t *testing.T) {
+	tests := []struct {
+		renderer Renderer
+		want     string
+	}{
+		{
+			renderer: csvRenderer{},
+			want: "price,src,dst,start,end,deal,url\n" +
+				"250,SFO,JFK,2030-03-01,2030-03-04,true,https://example.com/a\n" +
+				"310,OAK,EWR,2030-03-02,2030-03-05,unknown,https://example.com/b\n",
+		},
+		{
+			renderer: markdownRenderer{},
+			want: "| price | src | dst | start | end | deal | url |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| 250 | SFO | JFK | 2030-03-01 | 2030-03-04 | true | https://example.com/a |\n" +
+				"| 310 | OAK | EWR | 2030-03-02 | 2030-03-05 | unknown | https://example.com/b |\n",
+		},
+		{
+			renderer: tsvRenderer{},
+			want: "price\tsrc\tdst\tstart\tend\tdeal\turl\n" +
+				"250\tSFO\tJFK\t2030-03-01\t2030-03-04\ttrue\thttps://example.com/a\n" +
+				"310\tOAK\tEWR\t2030-03-02\t2030-03-05\tunknown\thttps://example.com/b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tt.renderer.Render(&buf, renderMessages); err != nil {
+			t.Fatalf("%T: %v", tt.renderer, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%T rendered\n%s\nwant\n%s", tt.renderer, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestHTMLRendererColumns(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (htmlRenderer{}).Render(&buf, renderMessages); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	header := strings.Count(lines[1], "<th>")
+	for _, row := range lines[2 : len(lines)-1] {
+		if cells := strings.Count(row, "<td>"); cells != header {
+			t.Errorf("row has %d cells, header has %d: %s", cells, header, row)
+		}
+	}
+}
+
+func TestPrettyJSONColors(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	tests := []struct {
+		color     string
+		wantColor bool
+	}{
+		{color: colorAuto, wantColor: false}, // go test does not run with a terminal on stdout
+		{color: colorNever, wantColor: false},
+		{color: colorAlways, wantColor: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--format=pretty-json", "--color=" + tt.color})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := cfg.renderer().Render(&buf, renderMessages); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(buf.String(), "[\n  {\n    ") {
+				t.Errorf("output is not indented:\n%s", buf.String())
+			}
+			if hasColor := strings.Contains(buf.String(), "\x1b["); hasColor != tt.wantColor {
+				t.Errorf("output has colors %v, want %v:\n%q", hasColor, tt.wantColor, buf.String())
+			}
+		})
+	}
+}
+
+func TestTSVSanitizesFields(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/a", want: "https://example.com/a"},
+		{url: "a\tb", want: "a b"},
+		{url: "a\nb\r\nc\rd", want: "a b c d"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		m := Message{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Url: tt.url}
+		if err := (tsvRenderer{}).Render(&buf, []Message{m}); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got %d lines, want a header and one row: %q", len(lines), buf.String())
+		}
+		fields := strings.Split(lines[1], "\t")
+		if len(fields) != len(resultColumns) {
+			t.Fatalf("row has %d fields, want %d: %q", len(fields), len(resultColumns), lines[1])
+		}
+		if got := fields[len(fields)-1]; got != tt.want {
+			t.Errorf("url field %q, want %q", got, tt.want)
+		}
+	}
+}
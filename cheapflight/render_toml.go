@@ -0,0 +1,54 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlRenderer writes the results as a TOML document with an [[offers]] table
+// per result. Like yamlRenderer it goes through JSON so that the keys match
+// the json format.
+type tomlRenderer struct{}
+
+func (tomlRenderer) Render(w io.Writer, messages []Message) error {
+	b, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var results []interface{}
+	if err := dec.Decode(&results); err != nil {
+		return err
+	}
+
+	for i := range results {
+		results[i] = tomlValue(results[i])
+	}
+	return toml.NewEncoder(w).Encode(map[string]interface{}{"offers": results})
+}
+
+// tomlValue turns the JSON numbers in v into integers where they are whole,
+// so that prices are written as 312 rather than 312.0.
+func tomlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = tomlValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = tomlValue(item)
+		}
+	}
+	return v
+}
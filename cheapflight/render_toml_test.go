@@ -0,0 +1,50 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestTOMLRenderer(t *testing.T) {
+	messages := append([]Message{}, renderMessages...)
+	messages = append(messages, Message{
+		Price: 199, Src: "SJC", Dst: "LGA", Start: "2030-03-03", End: "2030-03-06",
+		Prices: map[string]float64{"EUR": 183.5}, PricePerMile: 0.08,
+	})
+
+	var buf bytes.Buffer
+	if err := (tomlRenderer{}).Render(&buf, messages); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "[[offers]]"); n != len(messages) {
+		t.Errorf("got %d [[offers]] tables, want %d:\n%s", n, len(messages), buf.String())
+	}
+	if !strings.Contains(buf.String(), "price = 250\n") {
+		t.Errorf("price is not written as an integer:\n%s", buf.String())
+	}
+
+	var doc struct {
+		Offers []map[string]interface{} `toml:"offers"`
+	}
+	if _, err := toml.Decode(buf.String(), &doc); err != nil {
+		t.Fatalf("output is not TOML: %v\n%s", err, buf.String())
+	}
+
+	// The keys mirror the json format, so the offers decode back into messages.
+	b, err := json.Marshal(doc.Offers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Message
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, messages) {
+		t.Errorf("decoded %+v, want %+v", decoded, messages)
+	}
+}
@@ -0,0 +1,30 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlRenderer writes the results as a YAML list. The results go through JSON
+// first so that field names and omitted fields match the json format exactly.
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, messages []Message) error {
+	b, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(b, &results); err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(results); err != nil {
+		return err
+	}
+	return enc.Close()
+}
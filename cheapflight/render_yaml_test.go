@@ -0,0 +1,43 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (yamlRenderer{}).Render(&buf, renderMessages); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, buf.String())
+	}
+	if len(results) != len(renderMessages) {
+		t.Fatalf("got %d results, want %d", len(results), len(renderMessages))
+	}
+	for _, field := range []string{"price", "src", "dst", "start", "end", "deal", "url"} {
+		if _, ok := results[0][field]; !ok {
+			t.Errorf("result has no %q field:\n%s", field, buf.String())
+		}
+	}
+
+	// The fields mirror the json format, so the results decode back into messages.
+	b, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []Message
+	if err := json.Unmarshal(b, &messages); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(messages, renderMessages) {
+		t.Errorf("decoded %+v, want %+v", messages, renderMessages)
+	}
+}
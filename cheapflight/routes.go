@@ -0,0 +1,96 @@
+package cheapflight
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// route is one source and destination pair searched by a request. Both sides
+// use the same location list syntax as the positional arguments.
+type route struct {
+	Src string
+	Dst string
+	// Direction labels the results of the route with --reverse.
+	Direction string
+	// Notify is the comma separated channels alerting on the route in place
+	// of --notify, empty to use --notify.
+	Notify string
+}
+
+const (
+	directionOutbound = "outbound"
+	directionReturn   = "return"
+)
+
+// withReverse follows every route with its reverse, for --reverse, labelling
+// each with its direction so the two are reported side by side.
+func withReverse(routes []route) []route {
+	var both []route
+	for _, r := range routes {
+		both = append(both,
+			route{Src: r.Src, Dst: r.Dst, Direction: directionOutbound, Notify: r.Notify},
+			route{Src: r.Dst, Dst: r.Src, Direction: directionReturn, Notify: r.Notify},
+		)
+	}
+	return both
+}
+
+func (r route) notifiers() []string {
+	return splitList(r.Notify, ",")
+}
+
+func (r route) String() string {
+	return r.Src + " → " + r.Dst
+}
+
+// apply returns a copy of args searching r instead of the positional locations.
+// Airports whose city is searched too are dropped, see withoutCoveredAirports.
+func (r route) apply(args flights.PriceGraphArgs, sep string) flights.PriceGraphArgs {
+	args.SrcAirports, args.SrcCities = parseLocations(r.Src, sep)
+	args.DstAirports, args.DstCities = parseLocations(r.Dst, sep)
+	args.SrcAirports = withoutCoveredAirports(args.SrcAirports, args.SrcCities)
+	args.DstAirports = withoutCoveredAirports(args.DstAirports, args.DstCities)
+	return args
+}
+
+// loadRoutes reads a routes file with one "SRC DST" pair per line, optionally
+// followed by the comma separated channels to alert through for the route,
+// e.g. "SFO JFK webhook,email". Blank lines and lines starting with # are
+// ignored.
+func loadRoutes(path string) ([]route, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var routes []route
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected \"SRC DST [CHANNELS]\", got %q", path, line, text)
+		}
+		r := route{Src: fields[0], Dst: fields[1]}
+		if len(fields) == 3 {
+			r.Notify = fields[2]
+		}
+		routes = append(routes, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("%s: no routes", path)
+	}
+	return routes, nil
+}
@@ -0,0 +1,58 @@
+package cheapflight
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestReverse(t *testing.T) {
+	routes := withReverse([]route{{Src: "SFO", Dst: "JFK"}, {Src: "OAK", Dst: "BOS"}})
+	want := []route{
+		{Src: "SFO", Dst: "JFK", Direction: directionOutbound},
+		{Src: "JFK", Dst: "SFO", Direction: directionReturn},
+		{Src: "OAK", Dst: "BOS", Direction: directionOutbound},
+		{Src: "BOS", Dst: "OAK", Direction: directionReturn},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Fatalf("withReverse() = %+v, want %+v", routes, want)
+	}
+
+	api := routeStub()
+	offers := api.offers
+	api.offers = func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		found, priceRange, err := offers(ctx, args)
+		for i := range found {
+			found[i].SrcAirportCode, found[i].DstAirportCode = args.SrcAirports[0], args.DstAirports[0]
+		}
+		return found, priceRange, err
+	}
+	messages, err := searchRoutes(routes[:2], routeArgs(), "", withStub(Config{}, api), make(map[route][]float64), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queried []string
+	for _, args := range api.priceGraphCalls {
+		queried = append(queried, args.SrcAirports[0]+"-"+args.DstAirports[0])
+	}
+	if got := strings.Join(queried, ", "); got != "SFO-JFK, JFK-SFO" {
+		t.Errorf("queried %s, want both directions", got)
+	}
+
+	wantHeadings := []string{"Outbound SFO → JFK\n", "Return JFK → SFO\n"}
+	if len(messages) != len(wantHeadings) {
+		t.Fatalf("got %d results, want one per direction: %+v", len(messages), messages)
+	}
+	for i, m := range messages {
+		if m.Direction != routes[i].Direction {
+			t.Errorf("result %d labeled %q, want %q", i, m.Direction, routes[i].Direction)
+		}
+		if body := FormatMessageBody(m); !strings.HasPrefix(body, wantHeadings[i]) {
+			t.Errorf("result %d does not start with %q:\n%s", i, wantHeadings[i], body)
+		}
+	}
+}
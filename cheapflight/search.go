@@ -0,0 +1,381 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+	"golang.org/x/sync/errgroup"
+)
+
+// offerSet is every offer a search found along with the price range Google
+// Flights reported for each trip, keyed by tripKey. Each query has its own
+// range, which differs between return dates of the same departure.
+type offerSet struct {
+	offers      []flights.FullOffer
+	priceRanges map[string]*flights.PriceRange
+	// partial is set when trips were skipped because of --limit-api-calls.
+	partial bool
+}
+
+// errNoOffers is returned by a fixed-date search the flights API had no
+// offers for.
+var errNoOffers = errors.New("unable to obtain offers for this flight request")
+
+type offerFinder func(ctx context.Context, session *sessionManager, args flights.PriceGraphArgs, excludedAirline string, cfg Config) (offerSet, error)
+
+func dateKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// tripKey identifies the query for a departure and return date. Offers carry
+// the return date they were queried with, so tripKey(o.StartDate, o.ReturnDate)
+// finds the price range of the query that returned o.
+func tripKey(departure, ret time.Time) string {
+	return dateKey(departure) + "/" + dateKey(ret)
+}
+
+// calendarDate returns midnight UTC of t's date in t's own time zone. Offer
+// times are in the departure airport's zone, while the flights API truncates
+// dates to UTC days, which moves early morning departures east of UTC to the
+// previous day. Passing calendar dates keeps queries and booking links on the
+// day the flight actually departs.
+func calendarDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// SearchOffers runs a request and returns its cheapest offer, or the cheapest
+// offer for each airport pair with --per-pair. It returns an error if nothing was
+// found. With --class-fallback a search that fails is retried in each fallback
+// cabin class in turn, and the results report the class they were found in.
+func SearchOffers(args flights.PriceGraphArgs, excludedAirline string, cfg Config) ([]Message, error) {
+	messages, err := searchClass(args, excludedAirline, cfg)
+	if len(cfg.ClassFallback) == 0 {
+		return messages, err
+	}
+
+	requested := args.Options.Class
+	for _, class := range cfg.ClassFallback {
+		if err == nil || errors.Is(err, errAPICallLimit) {
+			break
+		}
+		if class == args.Options.Class {
+			continue
+		}
+		cfg.logger().Info("no offers in cabin class, trying the next", "class", className(args.Options.Class), "next", className(class), "err", err)
+		args.Options.Class = class
+		messages, err = searchClass(args, excludedAirline, cfg)
+	}
+	for i := range messages {
+		messages[i].Class = className(args.Options.Class)
+		if args.Options.Class != requested {
+			messages[i].RequestedClass = className(requested)
+		}
+	}
+	return messages, err
+}
+
+// searchClass searches in the cabin class of args. With --fallback-to-city a
+// failed airport search is retried with the city of each airport, and with
+// --split-legs round trips are priced as two one-way trips.
+func searchClass(args flights.PriceGraphArgs, excludedAirline string, cfg Config) ([]Message, error) {
+	if cfg.SplitLegs && args.Options.TripType == flights.RoundTrip {
+		return searchSplitLegs(args, excludedAirline, cfg)
+	}
+
+	find := findOffersRange
+	if args.TripLength == fixedDates {
+		find = findOffersFixedDates
+	}
+	if !cfg.ReturnStartDate.IsZero() && args.Options.TripType == flights.RoundTrip {
+		find = findOffersDatePairs
+	}
+
+	messages, err := searchOffers(args, excludedAirline, cfg, find)
+	if err == nil || !cfg.FallbackToCity {
+		return messages, err
+	}
+	cityArgs, ok := withAirportCities(args)
+	if !ok {
+		return nil, err
+	}
+	cfg.logger().Info("no airport offers, retrying with cities", "err", err, "src", cityArgs.SrcCities, "dst", cityArgs.DstCities)
+	return searchOffers(cityArgs, excludedAirline, cfg, find)
+}
+
+func searchOffers(args flights.PriceGraphArgs, excludedAirline string, cfg Config, find offerFinder) ([]Message, error) {
+	session := cfg.session()
+
+	set, err := find(context.Background(), session, args, excludedAirline, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var best []flights.FullOffer
+	if cfg.PerPair {
+		best = cheapestPerPair(set.offers, excludedAirline, cfg)
+	} else if o := cfg.bestOffer(set.offers, excludedAirline); o.Price != 0 {
+		best = append(best, o)
+	}
+
+	if len(best) == 0 {
+		return nil, errors.New("failed to find a flight matching the request")
+	}
+
+	var messages []Message
+	for _, o := range best {
+		url, err := session.SerializeURL(
+			context.Background(),
+			flights.Args{
+				Date:        calendarDate(o.StartDate),
+				ReturnDate:  calendarDate(returnDate(o.ReturnDate, o.StartDate, args.Options)),
+				SrcAirports: []string{o.SrcAirportCode},
+				DstAirports: []string{o.DstAirportCode},
+				Options:     args.Options,
+			},
+		)
+		// An offer is still worth reporting once --limit-api-calls is reached,
+		// just without its booking link.
+		if err != nil && !errors.Is(err, errAPICallLimit) {
+			if !cfg.ContinueOnSerializeError {
+				return nil, fmt.Errorf("serializing booking link: %w", err)
+			}
+			cfg.logger().Warn("could not serialize booking link, reporting offer without it", "src", o.SrcAirportCode, "dst", o.DstAirportCode, "err", err)
+		}
+		if cfg.CanonicalURL {
+			url = canonicalURL(url)
+		}
+
+		message := newMessage(o, url)
+		message.Currency = args.Options.Currency.String()
+		message.PricePerMile = pricePerMile(o, args.Options.TripType)
+		message.PriceRange = set.priceRanges[tripKey(o.StartDate, o.ReturnDate)]
+		message.Partial = set.partial || errors.Is(err, errAPICallLimit)
+		if cfg.IncludeSegments {
+			message.Segments = newSegments(o)
+		}
+		if cfg.ConfirmPrice {
+			confirmMessage(&message, session, o, args.Options, excludedAirline, cfg)
+		}
+		if cfg.MinDealCount > 1 {
+			message.candidates = dealCandidates(set)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// returnDate drops the return date of one-way searches, where the price graph
+// can still report one. The flights API ignores the return date of one-way
+// trips but rejects one before the departure, so the departure is used instead.
+func returnDate(ret, departure time.Time, options flights.Options) time.Time {
+	if options.TripType != flights.RoundTrip {
+		return departure
+	}
+	return ret
+}
+
+func findOffersRange(ctx context.Context, session *sessionManager, args flights.PriceGraphArgs, excludedAirline string, cfg Config) (offerSet, error) {
+	priceGraphOffers, err := session.GetPriceGraph(
+		ctx,
+		args,
+	)
+	if err != nil {
+		return offerSet{}, err
+	}
+
+	var trips []datedTrip
+	for _, priceGraphOffer := range priceGraphOffers {
+		var ret time.Time
+		if args.Options.TripType == flights.RoundTrip {
+			ret = tripReturnDate(priceGraphOffer.StartDate, args.TripLength)
+		}
+		if cfg.excludesTrip(priceGraphOffer.StartDate, ret) {
+			cfg.logger().Info("skipping excluded date", "date", dateKey(priceGraphOffer.StartDate))
+			continue
+		}
+		trips = append(trips, datedTrip{
+			departure:    priceGraphOffer.StartDate,
+			ret:          ret,
+			expectOffers: priceGraphOffer.Price > 0,
+		})
+	}
+	return queryTrips(ctx, session, args, trips, excludedAirline, cfg)
+}
+
+// findOffersDatePairs queries every pairing of a departure in the outbound
+// range with a return in the --return-start-date to --return-end-date range.
+func findOffersDatePairs(ctx context.Context, session *sessionManager, args flights.PriceGraphArgs, excludedAirline string, cfg Config) (offerSet, error) {
+	var trips []datedTrip
+	for dep := args.RangeStartDate; !dep.After(args.RangeEndDate); dep = dep.AddDate(0, 0, 1) {
+		for ret := cfg.ReturnStartDate; !ret.After(cfg.ReturnEndDate); ret = ret.AddDate(0, 0, 1) {
+			if ret.Before(dep) || cfg.excludesTrip(dep, ret) {
+				continue
+			}
+			trips = append(trips, datedTrip{departure: dep, ret: ret})
+		}
+	}
+	if len(trips) == 0 {
+		return offerSet{}, errors.New("no return date falls on or after a departure date")
+	}
+	if len(trips) > maxDatePairs {
+		return offerSet{}, fmt.Errorf("%d departure and return date pairs to search, at most %d are allowed; narrow the date ranges", len(trips), maxDatePairs)
+	}
+	return queryTrips(ctx, session, args, trips, excludedAirline, cfg)
+}
+
+// datedTrip is one departure and return date pair to query offers for. A zero
+// ret is a one-way trip.
+type datedTrip struct {
+	departure time.Time
+	ret       time.Time
+	// expectOffers is set when the price graph had a price for the trip.
+	expectOffers bool
+}
+
+// queryTrips queries the offers of every trip concurrently. Trips left once
+// --limit-api-calls is reached are skipped rather than failing the search.
+func queryTrips(ctx context.Context, session *sessionManager, args flights.PriceGraphArgs, trips []datedTrip, excludedAirline string, cfg Config) (offerSet, error) {
+	stream := cfg.stream
+	bar := cfg.progress(len(trips))
+	defer bar.Finish()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentRequests)
+
+	var mu sync.Mutex
+	set := offerSet{priceRanges: make(map[string]*flights.PriceRange)}
+	results := newBoundedOffers(cfg.MaxResults, cfg.lessOffer)
+	for _, trip := range trips {
+		trip := trip
+		g.Go(func() error {
+			ret := returnDate(trip.ret, trip.departure, args.Options)
+			offers, priceRange, err := getOffers(
+				ctx,
+				session,
+				flights.Args{
+					Date:        trip.departure,
+					ReturnDate:  ret,
+					SrcCities:   args.SrcCities,
+					DstCities:   args.DstCities,
+					SrcAirports: args.SrcAirports,
+					DstAirports: args.DstAirports,
+					Options:     args.Options,
+				},
+				trip.expectOffers,
+				cfg,
+			)
+			if errors.Is(err, errAPICallLimit) {
+				// Report the dates searched before the limit was hit.
+				mu.Lock()
+				set.partial = true
+				mu.Unlock()
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			offers = limitOffers(cfg.filterOffers(offers, args.Options, excludedAirline), cfg.MaxOffersPerDate, cfg.lessOffer)
+			if err := stream.Emit(offers); err != nil {
+				return err
+			}
+
+			mu.Lock()
+			results.Add(offers...)
+			set.priceRanges[tripKey(trip.departure, ret)] = priceRange
+			mu.Unlock()
+			bar.Step()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return offerSet{}, err
+	}
+	set.offers = results.offers
+	return set, nil
+}
+
+// getOffers queries the offers for one date. With --retry-on-empty an empty
+// response is retried up to --max-retries times, while --retry-budget lasts,
+// when expectOffers is set, i.e. when the price graph had a price for the date,
+// as the flights then exist and the empty response is likely transient.
+func getOffers(ctx context.Context, session *sessionManager, args flights.Args, expectOffers bool, cfg Config) ([]flights.FullOffer, *flights.PriceRange, error) {
+	for attempt := 0; ; attempt++ {
+		offers, priceRange, err := session.GetOffers(ctx, args)
+		if err != nil || len(offers) > 0 || !expectOffers || !cfg.RetryOnEmpty || attempt >= cfg.MaxRetries || !cfg.retries.take() {
+			return offers, priceRange, err
+		}
+
+		cfg.logger().Info("no offers returned, retrying", "date", dateKey(args.Date), "attempt", attempt+1)
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(cfg.retryDelay(attempt)):
+		}
+	}
+}
+
+func findOffersFixedDates(ctx context.Context, session *sessionManager, args flights.PriceGraphArgs, excludedAirline string, cfg Config) (offerSet, error) {
+	if cfg.excludesTrip(args.RangeStartDate, returnDate(args.RangeEndDate, time.Time{}, args.Options)) {
+		return offerSet{}, errors.New("the requested dates are excluded by --exclude-dates, --depart-days, --return-days or --min-advance-days")
+	}
+
+	ret := returnDate(args.RangeEndDate, args.RangeStartDate, args.Options)
+	offers, priceRange, err := session.GetOffers(
+		ctx,
+		flights.Args{
+			Date:        args.RangeStartDate,
+			ReturnDate:  ret,
+			SrcCities:   args.SrcCities,
+			DstCities:   args.DstCities,
+			SrcAirports: args.SrcAirports,
+			DstAirports: args.DstAirports,
+			Options:     args.Options,
+		},
+	)
+	if err != nil {
+		return offerSet{}, fmt.Errorf("unable to obtain offers for this flight request: %w", err)
+	}
+	if len(offers) == 0 {
+		return offerSet{}, errNoOffers
+	}
+
+	offers = limitOffers(cfg.filterOffers(offers, args.Options, excludedAirline), cfg.MaxOffersPerDate, cfg.lessOffer)
+	if err := cfg.stream.Emit(offers); err != nil {
+		cfg.logger().Error("streaming offers", "err", err)
+	}
+
+	results := newBoundedOffers(cfg.MaxResults, cfg.lessOffer)
+	results.Add(offers...)
+
+	return offerSet{
+		offers:      results.offers,
+		priceRanges: map[string]*flights.PriceRange{tripKey(args.RangeStartDate, ret): priceRange},
+	}, nil
+}
+
+// cheapestPerPair returns the best offer for every source and destination
+// airport pair, ordered by price.
+func cheapestPerPair(offers []flights.FullOffer, excludedAirline string, cfg Config) []flights.FullOffer {
+	pairs := make(map[[2]string][]flights.FullOffer)
+	for _, o := range offers {
+		pair := [2]string{o.SrcAirportCode, o.DstAirportCode}
+		pairs[pair] = append(pairs[pair], o)
+	}
+
+	var best []flights.FullOffer
+	for _, pairOffers := range pairs {
+		if o := cfg.bestOffer(pairOffers, excludedAirline); o.Price != 0 {
+			best = append(best, o)
+		}
+	}
+	sort.Slice(best, func(i, j int) bool { return cfg.lessOffer(best[i], best[j]) })
+	return best
+}
@@ -0,0 +1,776 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+var (
+	day1 = time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+	day3 = day1.AddDate(0, 0, 2)
+	day4 = day1.AddDate(0, 0, 3)
+)
+
+func roundTrip() flights.Options {
+	return flights.Options{Travelers: flights.Travelers{Adults: 1}, TripType: flights.RoundTrip, Stops: flights.AnyStops}
+}
+
+func offerOn(date time.Time, price float64) flights.FullOffer {
+	return flights.FullOffer{Offer: flights.Offer{StartDate: date, ReturnDate: date.AddDate(0, 0, 3), Price: price}, SrcAirportCode: "SFO", DstAirportCode: "JFK"}
+}
+
+func TestQueryTripsCancelsOnError(t *testing.T) {
+	tests := []struct {
+		name          string
+		failOn        time.Time
+		wantErr       error
+		wantCancelled int32
+		wantOffers    int
+	}{
+		{name: "all succeed", wantOffers: 4},
+		{name: "one fails", failOn: day2, wantErr: errBoom, wantCancelled: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started sync.WaitGroup
+			started.Add(3)
+			var cancelled atomic.Int32
+			api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				if tt.failOn.IsZero() {
+					return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+				}
+				if args.Date.Equal(tt.failOn) {
+					started.Wait()
+					return nil, nil, errBoom
+				}
+				started.Done()
+				select {
+				case <-ctx.Done():
+					cancelled.Add(1)
+					return nil, nil, ctx.Err()
+				case <-time.After(5 * time.Second):
+					return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+				}
+			}}
+			cfg := withStub(Config{}, api)
+
+			var trips []datedTrip
+			for _, d := range []time.Time{day1, day2, day3, day4} {
+				trips = append(trips, datedTrip{departure: d, ret: d.AddDate(0, 0, 3)})
+			}
+			set, err := queryTrips(context.Background(), cfg.session(), flights.PriceGraphArgs{Options: roundTrip()}, trips, "", cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("queryTrips() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := cancelled.Load(); got != tt.wantCancelled {
+				t.Errorf("%d queries cancelled, want %d", got, tt.wantCancelled)
+			}
+			if len(set.offers) != tt.wantOffers {
+				t.Errorf("got %d offers, want %d", len(set.offers), tt.wantOffers)
+			}
+		})
+	}
+}
+
+func TestLangReachesEveryCall(t *testing.T) {
+	for _, lang := range []string{"de", "fr", "ja"} {
+		t.Run(lang, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--lang=" + lang})
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &stubAPI{
+				priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+					return []flights.Offer{{StartDate: day1, ReturnDate: day1.AddDate(0, 0, 3), Price: 100}}, nil
+				},
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+				},
+			}
+			cfg = withStub(cfg, api)
+
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+			cfg.applyOptions(&args.Options)
+			if _, err := SearchOffers(args, "", cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(api.priceGraphCalls) == 0 || len(api.offerCalls) == 0 {
+				t.Fatalf("got %d price graph and %d offers calls, want both", len(api.priceGraphCalls), len(api.offerCalls))
+			}
+			for _, call := range api.priceGraphCalls {
+				if call.Options.Lang.String() != lang {
+					t.Errorf("GetPriceGraph Lang = %s, want %s", call.Options.Lang, lang)
+				}
+			}
+			for _, call := range api.offerCalls {
+				if call.Options.Lang.String() != lang {
+					t.Errorf("GetOffers Lang = %s, want %s", call.Options.Lang, lang)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxOffersPerDate(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		var offers []flights.FullOffer
+		for _, p := range []float64{500, 200, 400, 100, 300} {
+			offers = append(offers, offerOn(args.Date, p))
+		}
+		return offers, nil, nil
+	}}
+	cfg := withStub(Config{MaxOffersPerDate: 2}, api)
+
+	trips := []datedTrip{{departure: day1, ret: day1.AddDate(0, 0, 3)}, {departure: day2, ret: day2.AddDate(0, 0, 3)}}
+	set, err := queryTrips(context.Background(), cfg.session(), flights.PriceGraphArgs{Options: roundTrip()}, trips, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perDate := make(map[string][]float64)
+	for _, o := range set.offers {
+		perDate[dateKey(o.StartDate)] = append(perDate[dateKey(o.StartDate)], o.Price)
+	}
+	for _, d := range []time.Time{day1, day2} {
+		got := perDate[dateKey(d)]
+		if len(got) != 2 || got[0]+got[1] != 300 {
+			t.Errorf("%s kept %v, want the cheapest 100 and 200", dateKey(d), got)
+		}
+	}
+}
+
+func TestCapsSkipExcludedAirline(t *testing.T) {
+	api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+		return []flights.FullOffer{flownBy("Spirit", 100), flownBy("Delta", 200), flownBy("Alaska", 300)}, nil, nil
+	}}
+	for _, cfg := range []Config{{MaxOffersPerDate: 1}, {MaxResults: 1}} {
+		var streamed bytes.Buffer
+		cfg.Format, cfg.IncludeSegments = formatNDJSON, true
+		cfg = withStub(cfg, api)
+		cfg.stream = cfg.newOfferStream(&streamed)
+
+		// The cheapest offer is on the excluded airline, so it must not take
+		// the only place left by the cap.
+		args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day1.AddDate(0, 0, 3), TripLength: fixedDates, Options: roundTrip()}
+		messages, err := SearchOffers(args, "Spirit", cfg)
+		if err != nil {
+			t.Fatalf("max offers per date %d, max results %d: %v", cfg.MaxOffersPerDate, cfg.MaxResults, err)
+		}
+		if len(messages) != 1 || messages[0].Price != 200 {
+			t.Errorf("max offers per date %d, max results %d: got %+v, want the 200 offer", cfg.MaxOffersPerDate, cfg.MaxResults, messages)
+		}
+		if strings.Contains(streamed.String(), "Spirit") {
+			t.Errorf("streamed %s, want no offer of the excluded airline", streamed.String())
+		}
+	}
+}
+
+func TestCheapestPerPair(t *testing.T) {
+	pairOffer := func(src, dst string, price float64) flights.FullOffer {
+		o := offerOn(day1, price)
+		o.SrcAirportCode, o.DstAirportCode = src, dst
+		return o
+	}
+	tests := []struct {
+		name   string
+		offers []flights.FullOffer
+		want   []string
+	}{
+		{
+			name: "two pairs",
+			offers: []flights.FullOffer{
+				pairOffer("SFO", "JFK", 300), pairOffer("OAK", "JFK", 280),
+				pairOffer("SFO", "JFK", 250), pairOffer("OAK", "JFK", 320),
+			},
+			want: []string{"SFO-JFK 250", "OAK-JFK 280"},
+		},
+		{
+			name:   "unpriced offers skipped",
+			offers: []flights.FullOffer{pairOffer("SFO", "JFK", 0), pairOffer("SFO", "EWR", 400)},
+			want:   []string{"SFO-EWR 400"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, o := range cheapestPerPair(tt.offers, "", Config{}) {
+				got = append(got, fmt.Sprintf("%s-%s %v", o.SrcAirportCode, o.DstAirportCode, o.Price))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cheapestPerPair() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOneWayReturnDate(t *testing.T) {
+	ret := day1.AddDate(0, 0, 5)
+	searches := []struct {
+		name   string
+		search offerFinder
+		args   flights.PriceGraphArgs
+	}{
+		{name: "date range", search: findOffersRange, args: flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day1, TripLength: 5}},
+		{name: "fixed dates", search: findOffersFixedDates, args: flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: ret, TripLength: fixedDates}},
+	}
+	tests := []struct {
+		name     string
+		tripType flights.TripType
+		want     time.Time
+	}{
+		// The flights API rejects a return date before the departure, so
+		// one-way searches send the departure rather than a zero date.
+		{name: "one way", tripType: flights.OneWay, want: day1},
+		{name: "round trip", tripType: flights.RoundTrip, want: ret},
+	}
+	for _, search := range searches {
+		for _, tt := range tests {
+			t.Run(search.name+"/"+tt.name, func(t *testing.T) {
+				api := &stubAPI{
+					priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+						// The price graph reports a return date even for one-way trips.
+						return []flights.Offer{{StartDate: day1, ReturnDate: day3, Price: 100}}, nil
+					},
+					offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+						return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+					},
+				}
+				cfg := withStub(Config{}, api)
+				args := search.args
+				args.Options = roundTrip()
+				args.Options.TripType = tt.tripType
+				if _, err := search.search(context.Background(), cfg.session(), args, "", cfg); err != nil {
+					t.Fatal(err)
+				}
+
+				if len(api.offerCalls) != 1 {
+					t.Fatalf("got %d offers calls, want 1", len(api.offerCalls))
+				}
+				if got := api.offerCalls[0].ReturnDate; !got.Equal(tt.want) {
+					t.Errorf("GetOffers ReturnDate = %s, want %s", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestRetryOnEmpty(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		empties      int // responses that are empty before offers are returned
+		expectOffers bool
+		wantCalls    int
+		wantOffers   int
+	}{
+		{name: "empty then populated", cfg: Config{RetryOnEmpty: true, MaxRetries: 3}, empties: 1, expectOffers: true, wantCalls: 2, wantOffers: 1},
+		{name: "retries disabled", cfg: Config{MaxRetries: 3}, empties: 1, expectOffers: true, wantCalls: 1},
+		{name: "no price in the graph", cfg: Config{RetryOnEmpty: true, MaxRetries: 3}, empties: 1, wantCalls: 1},
+		{name: "gives up after max retries", cfg: Config{RetryOnEmpty: true, MaxRetries: 2}, empties: 5, expectOffers: true, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			empties := tt.empties
+			api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				if empties > 0 {
+					empties--
+					return nil, nil, nil
+				}
+				return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+			}}
+			cfg := withStub(tt.cfg, api)
+			cfg.backoff = newBackoff(1)
+
+			offers, _, err := getOffers(context.Background(), cfg.session(), flights.Args{Date: day1}, tt.expectOffers, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, calls, _ := api.calls(); calls != tt.wantCalls {
+				t.Errorf("made %d offers calls, want %d", calls, tt.wantCalls)
+			}
+			if len(offers) != tt.wantOffers {
+				t.Errorf("got %d offers, want %d", len(offers), tt.wantOffers)
+			}
+		})
+	}
+}
+
+func TestExcludeDatesNeverQueried(t *testing.T) {
+	tests := []struct {
+		exclude string
+		want    []string
+	}{
+		{exclude: "", want: []string{"2030-03-01", "2030-03-02", "2030-03-03", "2030-03-04"}},
+		{exclude: "2030-03-02", want: []string{"2030-03-01", "2030-03-03", "2030-03-04"}},
+		{exclude: "2030-03-01..2030-03-03", want: []string{"2030-03-04"}},
+		// Trips returning on an excluded date are dropped too.
+		{exclude: "2030-03-06", want: []string{"2030-03-01", "2030-03-02", "2030-03-04"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exclude, func(t *testing.T) {
+			var flags []string
+			if tt.exclude != "" {
+				flags = append(flags, "--exclude-dates="+tt.exclude)
+			}
+			cfg, err := ProcessFlags(flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &stubAPI{priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+				var offers []flights.Offer
+				for _, d := range []time.Time{day1, day2, day3, day4} {
+					offers = append(offers, flights.Offer{StartDate: d, Price: 100})
+				}
+				return offers, nil
+			}}
+			cfg = withStub(cfg, api)
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: 3, Options: roundTrip()}
+			if _, err := findOffersRange(context.Background(), cfg.session(), args, "", cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			var queried []string
+			for _, call := range api.offerCalls {
+				queried = append(queried, dateKey(call.Date))
+			}
+			sort.Strings(queried)
+			if !reflect.DeepEqual(queried, tt.want) {
+				t.Errorf("queried %q, want %q", queried, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackToCity(t *testing.T) {
+	tests := []struct {
+		name       string
+		fallback   bool
+		wantErr    bool
+		wantPrice  int
+		wantCities []string
+	}{
+		{name: "falls back to the city", fallback: true, wantPrice: 180, wantCities: []string{"San Francisco"}},
+		{name: "no fallback", fallback: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := func(args flights.PriceGraphArgs) []flights.Offer {
+				if len(args.SrcCities) == 0 {
+					return nil
+				}
+				return []flights.Offer{{StartDate: day1, Price: 180}}
+			}
+			api := &stubAPI{
+				priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+					return graph(args), nil
+				},
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					if len(args.SrcCities) == 0 {
+						return nil, nil, nil
+					}
+					return []flights.FullOffer{offerOn(args.Date, 180)}, nil, nil
+				},
+			}
+			cfg := withStub(Config{FallbackToCity: tt.fallback}, api)
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+
+			messages, err := SearchOffers(args, "", cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchOffers() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if messages[0].Price != tt.wantPrice {
+				t.Errorf("price = %d, want %d", messages[0].Price, tt.wantPrice)
+			}
+			last := api.priceGraphCalls[len(api.priceGraphCalls)-1]
+			if !reflect.DeepEqual(last.SrcCities, tt.wantCities) || len(last.SrcAirports) != 0 {
+				t.Errorf("fallback searched airports %q and cities %q, want cities %q", last.SrcAirports, last.SrcCities, tt.wantCities)
+			}
+		})
+	}
+}
+
+func TestIncludeSegments(t *testing.T) {
+	dep := day1.Add(8 * time.Hour)
+	offer := offerOn(day1, 250)
+	offer.Flight = []flights.Flight{
+		{AirlineName: "United", FlightNumber: "UA 123", DepAirportCode: "SFO", ArrAirportCode: "DEN", DepTime: dep, ArrTime: dep.Add(150 * time.Minute), Duration: 150 * time.Minute},
+		{AirlineName: "United", FlightNumber: "UA 456", DepAirportCode: "DEN", ArrAirportCode: "JFK", DepTime: dep.Add(4 * time.Hour), ArrTime: dep.Add(8 * time.Hour), Duration: 240 * time.Minute},
+	}
+	tests := []struct {
+		flags []string
+		want  []Segment
+	}{
+		{flags: nil},
+		{flags: []string{"--include-segments"}, want: []Segment{
+			{Airline: "United", FlightNumber: "UA 123", DepAirport: "SFO", ArrAirport: "DEN", DepTime: dep, ArrTime: dep.Add(150 * time.Minute), DurationMinutes: 150},
+			{Airline: "United", FlightNumber: "UA 456", DepAirport: "DEN", ArrAirport: "JFK", DepTime: dep.Add(4 * time.Hour), ArrTime: dep.Add(8 * time.Hour), DurationMinutes: 240},
+		}},
+	}
+	for _, tt := range tests {
+		cfg, err := ProcessFlags(append(tt.flags, "--format=json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			return []flights.FullOffer{offer}, nil, nil
+		}}
+		cfg = withStub(cfg, api)
+		args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, Options: roundTrip()}
+		messages, err := SearchOffers(args, "", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := cfg.renderer().Render(&buf, messages); err != nil {
+			t.Fatal(err)
+		}
+		var results []struct {
+			Segments []Segment `json:"segments"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+			t.Fatal(err)
+		}
+		if got := results[0].Segments; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: segments %+v, want %+v", tt.flags, got, tt.want)
+		}
+		if hasKey := strings.Contains(buf.String(), `"segments"`); hasKey != (tt.want != nil) {
+			t.Errorf("%q: output has a segments key %v:\n%s", tt.flags, hasKey, buf.String())
+		}
+	}
+}
+
+func TestContinueOnSerializeError(t *testing.T) {
+	tests := []struct {
+		flags   []string
+		wantErr bool
+	}{
+		{flags: nil},
+		{flags: []string{"--continue-on-serialize-error=true"}},
+		{flags: []string{"--continue-on-serialize-error=false"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg, err := ProcessFlags(tt.flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := &stubAPI{
+			offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				return []flights.FullOffer{offerOn(args.Date, 250)}, nil, nil
+			},
+			url: func(ctx context.Context, args flights.Args) (string, error) { return "", errBoom },
+		}
+		cfg = withStub(cfg, api)
+
+		args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day1, TripLength: fixedDates, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+		messages, err := SearchOffers(args, "", cfg)
+		if tt.wantErr {
+			if !errors.Is(err, errBoom) {
+				t.Errorf("%q: error %v, want the serialize error", tt.flags, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.flags, err)
+		}
+		if len(messages) != 1 || messages[0].Price != 250 || messages[0].Url != "" {
+			t.Errorf("%q: got %+v, want the offer without a link", tt.flags, messages)
+		}
+	}
+}
+
+func TestFindOffersDatePairs(t *testing.T) {
+	tests := []struct {
+		name             string
+		depStart, depEnd time.Time
+		retStart, retEnd time.Time
+		want             []string
+		wantErr          bool
+	}{
+		{
+			name:     "grid",
+			depStart: day1, depEnd: day2,
+			retStart: day2, retEnd: day4,
+			want: []string{
+				"2030-03-01>2030-03-02", "2030-03-01>2030-03-03", "2030-03-01>2030-03-04",
+				"2030-03-02>2030-03-02", "2030-03-02>2030-03-03", "2030-03-02>2030-03-04",
+			},
+		},
+		{
+			name:     "returns before departures",
+			depStart: day3, depEnd: day4,
+			retStart: day1, retEnd: day2,
+			wantErr: true,
+		},
+		{
+			name:     "too many pairs",
+			depStart: day1, depEnd: day1.AddDate(0, 0, 9),
+			retStart: day1.AddDate(0, 0, 10), retEnd: day1.AddDate(0, 0, 19),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{}
+			cfg := withStub(Config{ReturnStartDate: tt.retStart, ReturnEndDate: tt.retEnd}, api)
+			args := flights.PriceGraphArgs{RangeStartDate: tt.depStart, RangeEndDate: tt.depEnd, Options: roundTrip()}
+
+			_, err := findOffersDatePairs(context.Background(), cfg.session(), args, "", cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, call := range api.offerCalls {
+				got = append(got, dateKey(call.Date)+">"+dateKey(call.ReturnDate))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queried %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pricedEveryDay answers the price graph with a price for every day of the range.
+func pricedEveryDay(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+	var offers []flights.Offer
+	for d := args.RangeStartDate; !d.After(args.RangeEndDate); d = d.AddDate(0, 0, 1) {
+		offers = append(offers, flights.Offer{StartDate: d, ReturnDate: d.AddDate(0, 0, args.TripLength), Price: 100})
+	}
+	return offers, nil
+}
+
+func TestDepartAndReturnDays(t *testing.T) {
+	// 2030-03-01 and 2030-03-08 are Fridays, 2030-03-04 and 2030-03-11 Mondays.
+	outboundEnd := day1.AddDate(0, 0, 9)
+	tests := []struct {
+		name string
+		cfg  Config
+		args flights.PriceGraphArgs
+		find offerFinder
+		want []string
+	}{
+		{
+			name: "return date range",
+			cfg:  Config{ReturnStartDate: day1, ReturnEndDate: day1.AddDate(0, 0, 13)},
+			args: flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: outboundEnd, Options: roundTrip()},
+			find: findOffersDatePairs,
+			want: []string{"2030-03-01>2030-03-04", "2030-03-01>2030-03-11", "2030-03-08>2030-03-11"},
+		},
+		{
+			name: "trip length",
+			args: flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: outboundEnd, TripLength: 3, Options: roundTrip()},
+			find: findOffersRange,
+			want: []string{"2030-03-01>2030-03-04", "2030-03-08>2030-03-11"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.DepartDays, _ = parseWeekdays("fri")
+			tt.cfg.ReturnDays, _ = parseWeekdays("Monday")
+			api := &stubAPI{priceGraph: pricedEveryDay}
+			cfg := withStub(tt.cfg, api)
+
+			if _, err := tt.find(context.Background(), cfg.session(), tt.args, "", cfg); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, call := range api.offerCalls {
+				got = append(got, dateKey(call.Date)+">"+dateKey(call.ReturnDate))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queried %v, want only Friday to Monday trips %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinAdvanceDays(t *testing.T) {
+	today := day1.Add(15 * time.Hour)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day1.AddDate(0, 0, 19), TripLength: 3, Options: roundTrip()}
+	tests := []struct {
+		days      int
+		wantFirst string
+		wantDates int
+	}{
+		{days: 0, wantFirst: "2030-03-01", wantDates: 20},
+		{days: 14, wantFirst: "2030-03-15", wantDates: 6},
+		{days: 19, wantFirst: "2030-03-20", wantDates: 1},
+	}
+	for _, tt := range tests {
+		cfg, err := ProcessFlags([]string{fmt.Sprintf("--min-advance-days=%d", tt.days)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.clock = func() time.Time { return today }
+		api := &stubAPI{priceGraph: pricedEveryDay}
+		cfg = withStub(cfg, api)
+
+		if _, err := findOffersRange(context.Background(), cfg.session(), args, "", cfg); err != nil {
+			t.Fatal(err)
+		}
+		var departures []string
+		for _, call := range api.offerCalls {
+			departures = append(departures, dateKey(call.Date))
+		}
+		sort.Strings(departures)
+		if len(departures) != tt.wantDates || departures[0] != tt.wantFirst {
+			t.Errorf("--min-advance-days=%d queried %v, want %d dates from %s", tt.days, departures, tt.wantDates, tt.wantFirst)
+		}
+	}
+}
+
+func TestBookingLinkDates(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		departure time.Time
+	}{
+		{name: "early morning east of UTC", departure: time.Date(2030, 3, 1, 6, 30, 0, 0, tokyo)},
+		{name: "late evening west of UTC", departure: time.Date(2030, 3, 1, 23, 30, 0, 0, losAngeles)},
+		{name: "UTC", departure: day1.Add(12 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{
+				offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+					o := offerOn(day1, 250)
+					o.StartDate, o.ReturnDate = tt.departure, tt.departure.AddDate(0, 0, 3)
+					return []flights.FullOffer{o}, nil, nil
+				},
+				// The real serializer needs no connection for airports.
+				url: (&flights.Session{}).SerializeURL,
+			}
+			cfg := withStub(Config{}, api)
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+
+			messages, err := SearchOffers(args, "", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			link, err := url.Parse(messages[0].Url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tfs, err := base64.RawURLEncoding.DecodeString(link.Query().Get("tfs"))
+			if err != nil {
+				t.Fatalf("booking link %s: %v", messages[0].Url, err)
+			}
+			for _, want := range []string{"2030-03-01", "2030-03-04"} {
+				if !bytes.Contains(tfs, []byte(want)) {
+					t.Errorf("booking link does not search %s: %q", want, tfs)
+				}
+			}
+			for _, shifted := range []string{"2030-02-28", "2030-03-02"} {
+				if bytes.Contains(tfs, []byte(shifted)) {
+					t.Errorf("booking link searches %s: %q", shifted, tfs)
+				}
+			}
+		})
+	}
+}
+
+func TestClassFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		fallback      string
+		available     []flights.Class
+		wantClasses   []string
+		wantClass     string
+		wantRequested string
+		wantErr       bool
+	}{
+		{
+			name: "business returns nothing", fallback: "premium-economy,economy", available: []flights.Class{flights.PremiumEconomy, flights.Economy},
+			wantClasses: []string{"business", "premium-economy"}, wantClass: "premium-economy", wantRequested: "business",
+		},
+		{
+			name: "business has offers", fallback: "premium-economy,economy", available: []flights.Class{flights.Business, flights.PremiumEconomy},
+			wantClasses: []string{"business"}, wantClass: "business",
+		},
+		{
+			name: "requested class in the list is not searched twice", fallback: "business,economy", available: []flights.Class{flights.Economy},
+			wantClasses: []string{"business", "economy"}, wantClass: "economy", wantRequested: "business",
+		},
+		{
+			name: "no class has offers", fallback: "premium-economy",
+			wantClasses: []string{"business", "premium-economy"}, wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--class-fallback=" + tt.fallback})
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &stubAPI{offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				for _, class := range tt.available {
+					if args.Options.Class == class {
+						return []flights.FullOffer{offerOn(args.Date, 900)}, nil, nil
+					}
+				}
+				return nil, nil, nil
+			}}
+			cfg = withStub(cfg, api)
+
+			options := roundTrip()
+			options.Class = flights.Business
+			args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: fixedDates, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: options}
+			messages, err := SearchOffers(args, "", cfg)
+
+			var searched []string
+			for _, call := range api.offerCalls {
+				searched = append(searched, className(call.Options.Class))
+			}
+			if strings.Join(searched, ",") != strings.Join(tt.wantClasses, ",") {
+				t.Errorf("searched %q, want %q", searched, tt.wantClasses)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", messages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("got %d results, want 1", len(messages))
+			}
+			m := messages[0]
+			if m.Class != tt.wantClass || m.RequestedClass != tt.wantRequested {
+				t.Errorf("class %q requested %q, want %q requested %q", m.Class, m.RequestedClass, tt.wantClass, tt.wantRequested)
+			}
+			if tt.wantRequested != "" && !strings.Contains(FormatMessageBody(m), "In "+tt.wantClass+", as "+tt.wantRequested+" had no offers") {
+				t.Errorf("output does not report the class used:\n%s", FormatMessageBody(m))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cheapflight
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// searchFlags manage saved searches and are never saved in one.
+var searchFlags = map[string]bool{
+	"save-search":  true,
+	"load-search":  true,
+	"searches-dir": true,
+}
+
+// searchPath is the profile file of the saved search name in dir.
+func searchPath(dir, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid search name %q", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// saveSearch writes every flag set on fs, other than searchFlags, to the saved
+// search name.
+func saveSearch(fs *flag.FlagSet, dir, name string) error {
+	path, err := searchPath(dir, name)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if !searchFlags[f.Name] {
+			values[f.Name] = f.Value.String()
+		}
+	})
+	b, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// loadSearch sets the flags of the saved search name on fs, leaving flags
+// already set on the command line untouched.
+func loadSearch(fs *flag.FlagSet, dir, name string) error {
+	path, err := searchPath(dir, name)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for flagName, value := range values {
+		if set[flagName] || searchFlags[flagName] {
+			continue
+		}
+		if err := fs.Set(flagName, value); err != nil {
+			return fmt.Errorf("%s: --%s: %w", path, flagName, err)
+		}
+	}
+	return nil
+}
+
+// ListSearches writes the names of the searches saved in the --searches-dir
+// of args to w, one per line. It backs the list-searches command.
+func ListSearches(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("list-searches", flag.ContinueOnError)
+	dir := fs.String("searches-dir", "searches", "directory holding saved searches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("list-searches takes no arguments, got %q", fs.Args())
+	}
+
+	names, err := listSearches(*dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listSearches returns the names of the searches saved in dir.
+func listSearches(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
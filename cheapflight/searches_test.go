@@ -0,0 +1,89 @@
+package cheapflight
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestSaveAndLoadSearch(t *testing.T) {
+	saved := []string{"--lang=de", "--currency=EUR", "--max-results=7", "--outbound-class=business"}
+	tests := []struct {
+		name           string
+		flags          []string
+		wantLang       string
+		wantMaxResults int
+	}{
+		{name: "loaded as saved", wantLang: "de", wantMaxResults: 7},
+		{name: "command line overrides", flags: []string{"--lang=fr"}, wantLang: "fr", wantMaxResults: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			original, err := ProcessFlags(append(saved, "--searches-dir="+dir, "--save-search=trip"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := ProcessFlags(append(tt.flags, "--searches-dir="+dir, "--load-search=trip"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want, got flights.Options
+			original.applyOptions(&want)
+			loaded.applyOptions(&got)
+			if got.Lang.String() != tt.wantLang {
+				t.Errorf("Lang = %s, want %s", got.Lang, tt.wantLang)
+			}
+			got.Lang = want.Lang
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loaded options %+v, want %+v", got, want)
+			}
+			if loaded.MaxResults != tt.wantMaxResults {
+				t.Errorf("MaxResults = %d, want %d", loaded.MaxResults, tt.wantMaxResults)
+			}
+
+			names, err := listSearches(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, []string{"trip"}) {
+				t.Errorf("listSearches() = %q, want [trip]", names)
+			}
+		})
+	}
+}
+
+func TestListSearches(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"weekend", "holiday"} {
+		if _, err := ProcessFlags([]string{"--searches-dir=" + dir, "--save-search=" + name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ListSearches(&buf, []string{"--searches-dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "holiday\nweekend\n"; got != want {
+		t.Errorf("ListSearches() printed %q, want %q", got, want)
+	}
+
+	// Only --searches-dir applies to the command.
+	for _, args := range [][]string{{"--lang=de"}, {"trip"}} {
+		if err := ListSearches(&buf, args); err == nil {
+			t.Errorf("ListSearches(%q) succeeded, want an error", args)
+		}
+	}
+}
+
+func TestSearchNames(t *testing.T) {
+	for _, name := range []string{"", "../trip", ".hidden", "a/b"} {
+		if _, err := searchPath(t.TempDir(), name); err == nil {
+			t.Errorf("searchPath(%q) accepted an invalid name", name)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+const searchJSONFlag = "--search-json"
+
+// searchSpec is a whole request given as one JSON document with --search-json,
+// in place of the positional arguments and flags. Flags holds any other flag
+// by name, e.g. {"alliance": "star", "max-results": 5}.
+type searchSpec struct {
+	Src        string `json:"src"`
+	Dst        string `json:"dst"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	TripLength *int   `json:"trip_length"`
+	Travelers  int    `json:"travelers"`
+	Class      string `json:"class"`
+	OneWay     bool   `json:"one_way"`
+	// Stops is "any", "nonstop", or the most stops as a number, 1 or 2.
+	Stops            string         `json:"stops"`
+	ExcludedAirlines string         `json:"excluded_airlines"`
+	Target           float64        `json:"target"`
+	SMSNumber        string         `json:"sms_number"`
+	Flags            map[string]any `json:"flags"`
+}
+
+// searchStops maps the stops of a searchSpec to flights.Stops.
+var searchStops = map[string]flights.Stops{
+	"any":     flights.AnyStops,
+	"nonstop": flights.Nonstop,
+	"0":       flights.Nonstop,
+	"1":       flights.Stop1,
+	"2":       flights.Stop2,
+}
+
+// expandSearchJSON replaces a leading --search-json argument, naming a file or
+// holding the JSON itself, with the positional arguments and flags of its
+// spec. Arguments after it are kept as flags, overriding those of the spec.
+func expandSearchJSON(args []string) ([]string, error) {
+	if len(args) < 2 || !strings.HasPrefix(args[1], searchJSONFlag) {
+		return args, nil
+	}
+
+	value, rest := strings.TrimPrefix(args[1], searchJSONFlag), args[2:]
+	switch {
+	case strings.HasPrefix(value, "="):
+		value = value[1:]
+	case value == "" && len(rest) > 0:
+		value, rest = rest[0], rest[1:]
+	default:
+		return nil, fmt.Errorf("%s needs a file or a JSON document", searchJSONFlag)
+	}
+
+	spec, err := parseSearchSpec(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", searchJSONFlag, err)
+	}
+	expanded := append([]string{args[0]}, spec.args()...)
+	return append(expanded, rest...), nil
+}
+
+// parseSearchSpec reads a searchSpec from value, which is either the JSON
+// document itself or the path of a file holding it, and validates it.
+func parseSearchSpec(value string) (searchSpec, error) {
+	b := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		var err error
+		if b, err = os.ReadFile(value); err != nil {
+			return searchSpec{}, err
+		}
+	}
+
+	var spec searchSpec
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return searchSpec{}, err
+	}
+	return spec, spec.validate()
+}
+
+func (s searchSpec) validate() error {
+	if s.Src == "" || s.Dst == "" {
+		return errors.New("src and dst are required")
+	}
+	if s.StartDate == "" || s.EndDate == "" {
+		return errors.New("start_date and end_date are required")
+	}
+	if s.Travelers > maxTravelers {
+		return fmt.Errorf("travelers must be at most %d", maxTravelers)
+	}
+	if s.Class != "" {
+		if _, err := parseClass(s.Class); err != nil {
+			return err
+		}
+	}
+	if _, ok := searchStops[s.stops()]; !ok {
+		return fmt.Errorf("unknown stops %q, expected any, nonstop, 1 or 2", s.Stops)
+	}
+	if s.Target < 0 {
+		return errors.New("target must not be negative")
+	}
+	for name, value := range s.Flags {
+		if name == strings.TrimPrefix(searchJSONFlag, "--") {
+			return fmt.Errorf("flags must not contain %s", searchJSONFlag)
+		}
+		switch value.(type) {
+		case string, float64, bool:
+		default:
+			return fmt.Errorf("flag %q must be a string, number or boolean", name)
+		}
+	}
+	return nil
+}
+
+func (s searchSpec) stops() string {
+	if s.Stops == "" {
+		return "any"
+	}
+	return strings.ToLower(s.Stops)
+}
+
+// args returns the positional arguments and flags ProcessArgs and ProcessFlags
+// expect for the spec, with "default" for any positional argument it leaves out.
+func (s searchSpec) args() []string {
+	args := make([]string, numArgs-1)
+	for i := range args {
+		args[i] = "default"
+	}
+	args[startDateArg] = s.StartDate
+	args[endDateArg] = s.EndDate
+	args[durationArg] = strconv.Itoa(fixedDates)
+	if s.TripLength != nil {
+		args[durationArg] = strconv.Itoa(*s.TripLength)
+	}
+	args[startArg] = s.Src
+	args[endArg] = s.Dst
+	if s.Travelers > 0 {
+		args[travelerArg] = strconv.Itoa(s.Travelers)
+	}
+	if s.Class != "" {
+		class, _ := parseClass(s.Class)
+		args[classArg] = strconv.Itoa(int(class))
+	}
+	if s.OneWay {
+		args[tripTypeArg] = "OneWay"
+	}
+	args[stopArg] = strconv.Itoa(int(searchStops[s.stops()]))
+	if s.ExcludedAirlines != "" {
+		args[excludedAirlinesArg] = s.ExcludedAirlines
+	}
+	if s.Target > 0 {
+		args[targetArg] = strconv.FormatFloat(s.Target, 'f', -1, 64)
+	}
+	if s.SMSNumber != "" {
+		args[smsNumberArg] = s.SMSNumber
+	}
+
+	names := make([]string, 0, len(s.Flags))
+	for name := range s.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := s.Flags[name]
+		if f, ok := value.(float64); ok {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		args = append(args, fmt.Sprintf("--%s=%v", name, value))
+	}
+	return args
+}
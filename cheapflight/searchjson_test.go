@@ -0,0 +1,70 @@
+package cheapflight
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestSearchJSON(t *testing.T) {
+	const spec = `{
+		"src": "SFO", "dst": "JFK",
+		"start_date": "2030-03-01", "end_date": "2030-03-10", "trip_length": 4,
+		"travelers": 2, "class": "business", "stops": "1",
+		"excluded_airlines": "Spirit", "target": 320.5,
+		"flags": {"alliance": "star", "max-results": 5, "retry-on-empty": true}
+	}`
+	withArgs(t)
+	args, err := expandSearchJSON([]string{"runway", "--search-json=" + spec, "--max-results=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Args = args
+
+	cfg, err := ProcessFlags(os.Args[numArgs:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchArgs, excludedAirline, target, _, err := ProcessArgs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOptions := flights.Options{Travelers: flights.Travelers{Adults: 2}, Class: flights.Business, Stops: flights.Stop1, TripType: flights.RoundTrip}
+	got := searchArgs.Options
+	if got.Travelers != wantOptions.Travelers || got.Class != wantOptions.Class || got.Stops != wantOptions.Stops || got.TripType != wantOptions.TripType {
+		t.Errorf("options %+v, want %+v", got, wantOptions)
+	}
+	if !searchArgs.RangeStartDate.Equal(day1) || !searchArgs.RangeEndDate.Equal(time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)) || searchArgs.TripLength != 4 {
+		t.Errorf("dates %s to %s, %d nights", searchArgs.RangeStartDate, searchArgs.RangeEndDate, searchArgs.TripLength)
+	}
+	if excludedAirline != "Spirit" || target != 320.5 {
+		t.Errorf("excluded %q target %v, want Spirit and 320.5", excludedAirline, target)
+	}
+	// Flags after --search-json override those of the spec.
+	if cfg.Alliance != "star" || !cfg.RetryOnEmpty || cfg.MaxResults != 3 {
+		t.Errorf("filters alliance %q, retry on empty %v, max results %d", cfg.Alliance, cfg.RetryOnEmpty, cfg.MaxResults)
+	}
+}
+
+func TestSearchJSONValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "missing route", spec: `{"start_date": "2030-03-01", "end_date": "2030-03-10"}`},
+		{name: "missing dates", spec: `{"src": "SFO", "dst": "JFK"}`},
+		{name: "unknown field", spec: `{"src": "SFO", "dst": "JFK", "start_date": "2030-03-01", "end_date": "2030-03-10", "cabin": "first"}`},
+		{name: "unknown class", spec: `{"src": "SFO", "dst": "JFK", "start_date": "2030-03-01", "end_date": "2030-03-10", "class": "steerage"}`},
+		{name: "unknown stops", spec: `{"src": "SFO", "dst": "JFK", "start_date": "2030-03-01", "end_date": "2030-03-10", "stops": "3"}`},
+		{name: "too many travelers", spec: `{"src": "SFO", "dst": "JFK", "start_date": "2030-03-01", "end_date": "2030-03-10", "travelers": 12}`},
+		{name: "nested flag", spec: `{"src": "SFO", "dst": "JFK", "start_date": "2030-03-01", "end_date": "2030-03-10", "flags": {"alliance": ["star"]}}`},
+	}
+	for _, tt := range tests {
+		if _, err := expandSearchJSON([]string{"runway", "--search-json", tt.spec}); err == nil {
+			t.Errorf("%s: accepted %s", tt.name, tt.spec)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	twilio "github.com/twilio/twilio-go"
 	openapi "github.com/twilio/twilio-go/rest/api/v2010"
+	"log/slog"
 	"net/smtp"
 	"os"
 )
@@ -15,6 +16,14 @@ const (
 )
 
 func SendSMS(alertMessage string, recipient string) {
+	if err := sendSMS(alertMessage, recipient); err != nil {
+		slog.Error(err.Error())
+	} else {
+		slog.Info("SMS sent")
+	}
+}
+
+func sendSMS(alertMessage string, recipient string) error {
 	client := twilio.NewRestClient()
 
 	params := &openapi.CreateMessageParams{}
@@ -23,39 +32,100 @@ func SendSMS(alertMessage string, recipient string) {
 	params.SetBody(alertMessage)
 
 	_, err := client.Api.CreateMessage(params)
-	if err != nil {
-		fmt.Println(err.Error())
+	return err
+}
+
+func SendEmail(alertMessage string, recipient []string) {
+	if err := sendEmail(alertMessage, recipient); err != nil {
+		slog.Error(err.Error())
 	} else {
-		fmt.Println("SMS sent successfully!")
+		slog.Info("email sent")
 	}
 }
 
-func SendEmail(alertMessage string, recipient []string) {
+func sendEmail(alertMessage string, recipient []string) error {
 	sender := os.Getenv("FROM_EMAIL")
 	pwd := os.Getenv("PWD")
 	auth := smtp.PlainAuth("", sender, pwd, smtpserver)
-	err := smtp.SendMail(smtppair, auth, sender, recipient, []byte(alertMessage))
-	if err != nil {
-		fmt.Println(err.Error())
-	} else {
-		fmt.Println("Email sent successfully!")
-	}
+	return smtp.SendMail(smtppair, auth, sender, recipient, []byte(alertMessage))
 }
 
 func FormatMessageBody(m Message) string {
-	message := fmt.Sprintf("Lowest offer found at: price %d USD\n"+
+	message := directionHeading(m) + dealPrefix(m) + fmt.Sprintf("Lowest offer found at: price %d %s\n"+
 		"Flying out on %s\n"+
 		"Returning on %s\n"+
-		"Check it out here: %s", m.Price, m.Start, m.End, m.Url)
-	fmt.Println(message)
-	return message
+		"Check it out here: %s", m.Price, m.currency(), m.Start, m.End, m.Url)
+	return message + classSuffix(m) + pricesSuffix(m) + budgetSplitSuffix(m) + splitLegsSuffix(m) + partialSuffix(m)
+}
+
+// splitLegsSuffix describes the separately booked legs of a --split-legs result.
+func splitLegsSuffix(m Message) string {
+	if len(m.Legs) != 2 {
+		return ""
+	}
+	out, ret := m.Legs[0], m.Legs[1]
+	return fmt.Sprintf("\nBooked as two one-way tickets: outbound %d %s, return %d %s\n"+
+		"Return ticket here: %s", out.Price, out.currency(), ret.Price, ret.currency(), ret.Url)
 }
 
 func FormatMessageBodyTarget(m Message, target float64) string {
-	message := fmt.Sprintf("Flight under target %.2f: price %d USD\n"+
+	message := directionHeading(m) + dealPrefix(m) + fmt.Sprintf("Flight under target %.2f: price %d %s\n"+
 		"Flying out on %s\n"+
 		"Returning on %s\n"+
-		"Check it out here: %s", target, m.Price, m.Start, m.End, m.Url)
-	fmt.Println(message)
-	return message
+		"Check it out here: %s", target, m.Price, m.currency(), m.Start, m.End, m.Url)
+	return message + classSuffix(m) + pricesSuffix(m) + budgetSplitSuffix(m) + splitLegsSuffix(m) + partialSuffix(m)
+}
+
+// classSuffix shows the cabin class a --class-fallback result was found in.
+func classSuffix(m Message) string {
+	if m.RequestedClass != "" {
+		return fmt.Sprintf("\nIn %s, as %s had no offers", m.Class, m.RequestedClass)
+	}
+	if m.Class != "" {
+		return "\nIn " + m.Class
+	}
+	return ""
+}
+
+// partialSuffix notes that --limit-api-calls cut the search of m short.
+func partialSuffix(m Message) string {
+	if !m.Partial {
+		return ""
+	}
+	return "\nAPI call limit reached, results are partial"
+}
+
+// pricesSuffix shows the --display-currencies conversions of m.
+func pricesSuffix(m Message) string {
+	if len(m.Prices) == 0 {
+		return ""
+	}
+	return "\nAlso " + formatPrices(m)
+}
+
+// budgetSplitSuffix shows the total and per-person fare of a --budget-split result.
+func budgetSplitSuffix(m Message) string {
+	if m.Travelers == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nTotal %d %s for %d travelers, %.2f %s per person (split evenly)",
+		m.Price, m.currency(), m.Travelers, m.PerPersonPrice, m.currency())
+}
+
+// directionHeading names the route and direction of a --reverse result.
+func directionHeading(m Message) string {
+	switch m.Direction {
+	case directionOutbound:
+		return fmt.Sprintf("Outbound %s → %s\n", m.Src, m.Dst)
+	case directionReturn:
+		return fmt.Sprintf("Return %s → %s\n", m.Src, m.Dst)
+	}
+	return ""
+}
+
+func dealPrefix(m Message) string {
+	if m.Deal {
+		return "Deal! "
+	}
+	return ""
 }
@@ -0,0 +1,32 @@
+package cheapflight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetSplit(t *testing.T) {
+	tests := []struct {
+		travelers     int
+		wantPerPerson float64
+		wantSuffix    string
+	}{
+		{travelers: 3, wantPerPerson: 333.33, wantSuffix: "\nTotal 1000 USD for 3 travelers, 333.33 USD per person (split evenly)"},
+		{travelers: 1, wantPerPerson: 1000, wantSuffix: "\nTotal 1000 USD for 1 travelers, 1000.00 USD per person (split evenly)"},
+		{travelers: 0},
+	}
+	for _, tt := range tests {
+		m := Message{Price: 1000, Currency: "USD"}
+		m.splitBudget(tt.travelers)
+
+		if m.PerPersonPrice != tt.wantPerPerson {
+			t.Errorf("%d travelers: per person %v, want %v", tt.travelers, m.PerPersonPrice, tt.wantPerPerson)
+		}
+		if got := budgetSplitSuffix(m); got != tt.wantSuffix {
+			t.Errorf("%d travelers: suffix %q, want %q", tt.travelers, got, tt.wantSuffix)
+		}
+		if body := FormatMessageBody(m); !strings.Contains(body, tt.wantSuffix) {
+			t.Errorf("%d travelers: message body does not show the split:\n%s", tt.travelers, body)
+		}
+	}
+}
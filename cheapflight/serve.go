@@ -0,0 +1,99 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ajhingran/runway/proto/runwayv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SearchOnce runs a single check of the request in args, a command line like
+// os.Args, and returns its results without rendering, alerting or watching.
+// The error wraps ErrFailedRoutes if routes failed, in which case the results
+// of the other routes are still returned.
+func SearchOnce(args []string) ([]Message, error) {
+	return searchOnce(args, nil)
+}
+
+// searchOnce is SearchOnce, creating flights sessions with newSession instead
+// when it is set.
+func searchOnce(args []string, newSession func() (flightsAPI, error)) ([]Message, error) {
+	cfg, err := parseRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	defer cfg.Close()
+	if newSession != nil {
+		cfg.sessions.newSession = newSession
+	}
+
+	searchArgs, excludedAirline, _, _, err := ProcessArgs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.applyOptions(&searchArgs.Options)
+	if err := validateTravelers(searchArgs.Options.Travelers); err != nil {
+		return nil, err
+	}
+	routes, err := requestRoutes(searchArgs, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := searchRoutes(routes, searchArgs, excludedAirline, cfg, make(map[route][]float64), nil)
+	sortMessages(messages, cfg.Sort)
+	if cfg.Anonymize {
+		messages = anonymize(messages)
+	}
+	return messages, err
+}
+
+// NewGRPCServer returns a gRPC server answering the runway.v1.Runway service
+// of proto/runway.proto with SearchOnce. It backs the serve command.
+func NewGRPCServer() *grpc.Server {
+	s := grpc.NewServer()
+	runwayv1.RegisterRunwayServer(s, grpcServer{})
+	return s
+}
+
+type grpcServer struct {
+	runwayv1.UnimplementedRunwayServer
+	// newSession replaces the flights sessions of the searches when set.
+	newSession func() (flightsAPI, error)
+}
+
+func (s grpcServer) Search(ctx context.Context, req *runwayv1.SearchRequest) (*runwayv1.SearchResults, error) {
+	args := []string{"runway"}
+	for _, arg := range []string{
+		req.RangeStartDate,
+		req.RangeEndDate,
+		req.TripLength,
+		req.Src,
+		req.Dst,
+		req.Travelers,
+		req.Class,
+		req.TripType,
+		req.Stops,
+		req.ExcludedAirlines,
+		"", // target price, only used for alerts
+		"", // SMS number, only used for alerts
+	} {
+		if arg == "" {
+			arg = "default"
+		}
+		args = append(args, arg)
+	}
+	args = append(args, req.Flags...)
+
+	messages, err := searchOnce(args, s.newSession)
+	if errors.Is(err, ErrFailedRoutes) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return protobufResults(messages), nil
+}
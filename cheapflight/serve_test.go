@@ -0,0 +1,84 @@
+package cheapflight
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ajhingran/runway/proto/runwayv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcClient serves a grpcServer searching api over an in-memory connection.
+func grpcClient(t *testing.T, api flightsAPI) runwayv1.RunwayClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	runwayv1.RegisterRunwayServer(s, grpcServer{newSession: func() (flightsAPI, error) { return api, nil }})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return runwayv1.NewRunwayClient(conn)
+}
+
+func TestGRPCSearch(t *testing.T) {
+	client := grpcClient(t, routeStub("OAK"))
+	request := func() *runwayv1.SearchRequest {
+		return &runwayv1.SearchRequest{
+			RangeStartDate: "2030-03-01",
+			RangeEndDate:   "2030-03-02",
+			TripLength:     "3",
+			Src:            "SFO",
+			Dst:            "JFK",
+			Flags:          []string{"--currency=EUR", "--log-file=" + t.TempDir() + "/runway.log"},
+		}
+	}
+
+	resp, err := client.Search(context.Background(), request())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &runwayv1.SearchResults{Results: []*runwayv1.Result{{
+		Price:    100,
+		Currency: "EUR",
+		Src:      "SFO",
+		Dst:      "JFK",
+		Start:    "2030-02-28 16:00 PST (UTC-08:00)",
+		End:      "2030-03-04",
+		Url:      "https://www.google.com/travel/flights",
+	}}}
+	if !proto.Equal(resp, want) {
+		t.Errorf("Search() = %v, want %v", resp, want)
+	}
+
+	tests := []struct {
+		name string
+		edit func(*runwayv1.SearchRequest)
+		want codes.Code
+	}{
+		{name: "invalid request", edit: func(r *runwayv1.SearchRequest) { r.TripLength = "three" }, want: codes.InvalidArgument},
+		{name: "invalid flag", edit: func(r *runwayv1.SearchRequest) { r.Flags = []string{"--format=bogus"} }, want: codes.InvalidArgument},
+		{name: "failed route", edit: func(r *runwayv1.SearchRequest) { r.Src = "OAK" }, want: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := request()
+			tt.edit(req)
+			if _, err := client.Search(context.Background(), req); status.Code(err) != tt.want {
+				t.Errorf("Search() error = %v, want code %s", err, tt.want)
+			}
+		})
+	}
+}
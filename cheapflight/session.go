@@ -0,0 +1,189 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// flightsAPI is the part of flights.Session the searches call.
+type flightsAPI interface {
+	GetPriceGraph(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error)
+	GetOffers(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error)
+	SerializeURL(ctx context.Context, args flights.Args) (string, error)
+}
+
+func newFlightsSession() (flightsAPI, error) {
+	return flights.New()
+}
+
+// sessionManager shares one flights.Session across searches. The session is
+// recreated once it is older than refreshInterval, and whenever a call fails
+// with an auth-type error, in which case the call is retried on the new session.
+//
+// The concurrent searches share the session without further locking:
+// flights.Session is documented as safe for concurrent use, it only reads its
+// cookies and HTTP client after creation and caches city names in a sync.Map.
+// mu guards replacing the session, callers holding an older session keep using
+// it until their call returns.
+type sessionManager struct {
+	mu              sync.Mutex
+	session         flightsAPI
+	created         time.Time
+	refreshInterval time.Duration
+	requestTimeout  time.Duration
+	backoff         *backoff
+	retries         *budget
+	calls           *budget
+	fixtures        *fixtures
+	log             *slog.Logger
+	newSession      func() (flightsAPI, error)
+}
+
+func newSessionManager(refreshInterval, requestTimeout time.Duration, backoff *backoff, retries, calls *budget, log *slog.Logger) *sessionManager {
+	return &sessionManager{
+		refreshInterval: refreshInterval,
+		requestTimeout:  requestTimeout,
+		backoff:         backoff,
+		retries:         retries,
+		calls:           calls,
+		log:             log,
+		newSession:      newFlightsSession,
+	}
+}
+
+func (m *sessionManager) get() (flightsAPI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := m.refreshInterval > 0 && time.Since(m.created) > m.refreshInterval
+	if m.session == nil || expired {
+		return m.renew()
+	}
+	return m.session, nil
+}
+
+// refresh replaces stale with a new session, unless another caller already did.
+func (m *sessionManager) refresh(stale flightsAPI) (flightsAPI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.session != stale && m.session != nil {
+		return m.session, nil
+	}
+	return m.renew()
+}
+
+func (m *sessionManager) renew() (flightsAPI, error) {
+	session, err := m.newSession()
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	m.session = session
+	m.created = time.Now()
+	return session, nil
+}
+
+func (m *sessionManager) do(ctx context.Context, fn func(context.Context, flightsAPI) error) error {
+	session, err := m.get()
+	if err != nil {
+		return err
+	}
+
+	err = m.call(ctx, session, fn)
+	if !isAuthError(err) || !m.retries.take() {
+		return err
+	}
+
+	m.log.Warn("session rejected, refreshing", "err", err)
+	time.Sleep(m.backoff.Delay(0))
+	session, err = m.refresh(session)
+	if err != nil {
+		return err
+	}
+	return m.call(ctx, session, fn)
+}
+
+// call runs fn bounded by --request-timeout, so one slow call fails on its own
+// instead of holding up the whole search, and counts it towards --limit-api-calls.
+func (m *sessionManager) call(ctx context.Context, session flightsAPI, fn func(context.Context, flightsAPI) error) error {
+	if !m.calls.take() {
+		return errAPICallLimit
+	}
+	if m.requestTimeout <= 0 {
+		return fn(ctx, session)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, m.requestTimeout)
+	defer cancel()
+	err := fn(callCtx, session)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out after %s: %w", m.requestTimeout, err)
+	}
+	return err
+}
+
+func (m *sessionManager) GetPriceGraph(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+	var offers []flights.Offer
+	err := m.fixtures.run("price-graph", args, &offers, func() error {
+		return m.do(ctx, func(ctx context.Context, session flightsAPI) error {
+			var err error
+			offers, err = session.GetPriceGraph(ctx, args)
+			return err
+		})
+	})
+	return offers, err
+}
+
+func (m *sessionManager) GetOffers(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+	var result struct {
+		Offers     []flights.FullOffer
+		PriceRange *flights.PriceRange
+	}
+	err := m.fixtures.run("offers", args, &result, func() error {
+		return m.do(ctx, func(ctx context.Context, session flightsAPI) error {
+			var err error
+			result.Offers, result.PriceRange, err = session.GetOffers(ctx, args)
+			return err
+		})
+	})
+	return result.Offers, result.PriceRange, err
+}
+
+func (m *sessionManager) SerializeURL(ctx context.Context, args flights.Args) (string, error) {
+	var url string
+	err := m.fixtures.run("url", args, &url, func() error {
+		return m.do(ctx, func(ctx context.Context, session flightsAPI) error {
+			var err error
+			url, err = session.SerializeURL(ctx, args)
+			return err
+		})
+	})
+	return url, err
+}
+
+// sessionError explains the usual causes of failing to create a session, which
+// needs to reach Google Flights to obtain cookies.
+func sessionError(err error) error {
+	return fmt.Errorf("could not create a Google Flights session: %w\n"+
+		"check that this machine can reach https://www.google.com, "+
+		"that HTTPS_PROXY or HTTP_PROXY are set if you need a proxy, "+
+		"and wait a few minutes before retrying in case requests are being rate limited; "+
+		"./runway doctor checks each of these", err)
+}
+
+// isAuthError matches the status code errors the flights client returns when
+// Google rejects the session cookies.
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "status code: 401") || strings.Contains(msg, "status code: 403")
+}
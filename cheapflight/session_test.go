@@ -0,0 +1,254 @@
+package cheapflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// stubAPI stands in for flights.Session, answering each call with its func
+// field and recording the arguments of every call. Unset funcs return nothing.
+type stubAPI struct {
+	priceGraph func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error)
+	offers     func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error)
+	url        func(ctx context.Context, args flights.Args) (string, error)
+
+	mu              sync.Mutex
+	priceGraphCalls []flights.PriceGraphArgs
+	offerCalls      []flights.Args
+	urlCalls        []flights.Args
+}
+
+func (s *stubAPI) GetPriceGraph(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+	s.mu.Lock()
+	s.priceGraphCalls = append(s.priceGraphCalls, args)
+	s.mu.Unlock()
+	if s.priceGraph == nil {
+		return nil, nil
+	}
+	return s.priceGraph(ctx, args)
+}
+
+func (s *stubAPI) GetOffers(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+	s.mu.Lock()
+	s.offerCalls = append(s.offerCalls, args)
+	s.mu.Unlock()
+	if s.offers == nil {
+		return nil, nil, nil
+	}
+	return s.offers(ctx, args)
+}
+
+func (s *stubAPI) SerializeURL(ctx context.Context, args flights.Args) (string, error) {
+	s.mu.Lock()
+	s.urlCalls = append(s.urlCalls, args)
+	s.mu.Unlock()
+	if s.url == nil {
+		return "https://www.google.com/travel/flights", nil
+	}
+	return s.url(ctx, args)
+}
+
+// calls returns the number of calls made of each kind.
+func (s *stubAPI) calls() (priceGraph, offers, urls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.priceGraphCalls), len(s.offerCalls), len(s.urlCalls)
+}
+
+var discardLog = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// errBoom is an API failure that is neither an auth nor a timeout error.
+var errBoom = errors.New("boom")
+
+// withStub returns cfg searching through api instead of Google Flights.
+func withStub(cfg Config, api flightsAPI) Config {
+	cfg.log = discardLog
+	cfg.sessions = newSessionManager(cfg.RefreshInterval, cfg.RequestTimeout, newBackoff(1), cfg.retries, cfg.apiCalls, cfg.log)
+	cfg.sessions.newSession = func() (flightsAPI, error) { return api, nil }
+	return cfg
+}
+
+func TestSessionRefresh(t *testing.T) {
+	authErr := errors.New("unexpected status code: 401")
+	tests := []struct {
+		name            string
+		refreshInterval time.Duration
+		failures        []error // returned by the first calls of the first session
+		calls           int
+		wantSessions    int
+		wantErr         error
+	}{
+		{name: "healthy session is reused", calls: 2, wantSessions: 1},
+		{name: "auth error refreshes and retries", failures: []error{authErr}, calls: 1, wantSessions: 2},
+		{name: "forbidden refreshes and retries", failures: []error{errors.New("unexpected status code: 403")}, calls: 1, wantSessions: 2},
+		{name: "other errors are not retried", failures: []error{errBoom}, calls: 1, wantSessions: 1, wantErr: errBoom},
+		{name: "refresh interval recreates the session", refreshInterval: time.Nanosecond, calls: 2, wantSessions: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sessions []*stubAPI
+			m := newSessionManager(tt.refreshInterval, 0, newBackoff(1), nil, nil, discardLog)
+			m.newSession = func() (flightsAPI, error) {
+				failures := tt.failures
+				if len(sessions) > 0 {
+					failures = nil
+				}
+				api := &stubAPI{priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+					if len(failures) > 0 {
+						err := failures[0]
+						failures = failures[1:]
+						return nil, err
+					}
+					return []flights.Offer{{Price: 100}}, nil
+				}}
+				sessions = append(sessions, api)
+				return api, nil
+			}
+
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				time.Sleep(time.Microsecond)
+				_, err = m.GetPriceGraph(context.Background(), flights.PriceGraphArgs{})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPriceGraph error = %v, want %v", err, tt.wantErr)
+			}
+			if len(sessions) != tt.wantSessions {
+				t.Errorf("created %d sessions, want %d", len(sessions), tt.wantSessions)
+			}
+		})
+	}
+}
+
+func TestSessionCreationFailure(t *testing.T) {
+	dialErr := errors.New("dial tcp: lookup www.google.com: no such host")
+	m := newSessionManager(0, 0, newBackoff(1), nil, nil, discardLog)
+	m.newSession = func() (flightsAPI, error) { return nil, dialErr }
+
+	_, err := m.GetPriceGraph(context.Background(), flights.PriceGraphArgs{})
+	if !errors.Is(err, dialErr) {
+		t.Fatalf("error = %v, want it to wrap %v", err, dialErr)
+	}
+	for _, guidance := range []string{"could not create a Google Flights session", "https://www.google.com", "HTTPS_PROXY", "rate limited", "./runway doctor"} {
+		if !strings.Contains(err.Error(), guidance) {
+			t.Errorf("error %q does not mention %q", err, guidance)
+		}
+	}
+}
+
+// TestConcurrentSearches runs searches in parallel on one sessionManager that
+// keeps replacing its session, for go test -race to check the sharing.
+func TestConcurrentSearches(t *testing.T) {
+	const searches = 16
+
+	var mu sync.Mutex
+	var sessions []*stubAPI
+	cfg := withStub(Config{
+		RefreshInterval: time.Nanosecond,
+		retries:         newBudget(1000, discardLog, "retries"),
+		apiCalls:        newBudget(100000, discardLog, "api calls"),
+	}, nil)
+	cfg.sessions.newSession = func() (flightsAPI, error) {
+		api := &stubAPI{
+			priceGraph: pricedEveryDay,
+			offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				return []flights.FullOffer{offerOn(args.Date, 100+float64(args.Date.Day()))}, &flights.PriceRange{Low: 90, High: 200}, nil
+			},
+		}
+		mu.Lock()
+		sessions = append(sessions, api)
+		mu.Unlock()
+		return api, nil
+	}
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day4, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, searches)
+	for i := 0; i < searches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			messages, err := SearchOffers(args, "", cfg)
+			if err == nil && messages[0].Price != 101 {
+				err = fmt.Errorf("cheapest price %d, want 101", messages[0].Price)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Every search made a price graph call, one offers call per date and one
+	// booking link, spread over the sessions that replaced each other.
+	var priceGraphs, offers, urls int
+	for _, s := range sessions {
+		p, o, u := s.calls()
+		priceGraphs, offers, urls = priceGraphs+p, offers+o, urls+u
+	}
+	if priceGraphs != searches || offers != 4*searches || urls != searches {
+		t.Errorf("made %d price graph, %d offers and %d url calls, want %d, %d and %d", priceGraphs, offers, urls, searches, 4*searches, searches)
+	}
+	if len(sessions) < 2 {
+		t.Errorf("created %d sessions, want the session replaced during the searches", len(sessions))
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestTimeout time.Duration
+		overall        time.Duration
+		delay          time.Duration
+		wantErr        string
+	}{
+		{name: "slow call times out alone", requestTimeout: 20 * time.Millisecond, overall: 5 * time.Second, delay: time.Second, wantErr: "request timed out after 20ms"},
+		{name: "fast call", requestTimeout: time.Second, overall: 5 * time.Second, delay: 0},
+		{name: "overall deadline first", requestTimeout: time.Second, overall: 20 * time.Millisecond, delay: 5 * time.Second, wantErr: context.DeadlineExceeded.Error()},
+		{name: "no request timeout", overall: 20 * time.Millisecond, delay: 5 * time.Second, wantErr: context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(tt.delay):
+					return []flights.Offer{{Price: 100}}, nil
+				}
+			}}
+			cfg := withStub(Config{RequestTimeout: tt.requestTimeout}, api)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.overall)
+			defer cancel()
+
+			start := time.Now()
+			_, err := cfg.session().GetPriceGraph(ctx, flights.PriceGraphArgs{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("call took %s, want it cut short", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package cheapflight
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// searchSplitLegs prices a round trip as two one-way trips for --split-legs.
+// The outbound leg is searched over the requested dates, the return leg on the
+// date the cheapest outbound implies. The result is priced at the sum of both
+// legs and lists each leg in Legs.
+func searchSplitLegs(args flights.PriceGraphArgs, excludedAirline string, cfg Config) ([]Message, error) {
+	outArgs := args
+	outArgs.Options.TripType = flights.OneWay
+	outbound, err := SearchOffers(outArgs, excludedAirline, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("outbound leg: %w", err)
+	}
+	out := outbound[0]
+
+	returnDay := args.RangeEndDate
+	if args.TripLength != fixedDates {
+		returnDay = tripReturnDate(calendarDate(out.departure), args.TripLength)
+	}
+
+	retArgs := outArgs
+	retArgs.SrcAirports, retArgs.DstAirports = args.DstAirports, args.SrcAirports
+	retArgs.SrcCities, retArgs.DstCities = args.DstCities, args.SrcCities
+	retArgs.RangeStartDate, retArgs.RangeEndDate = returnDay, returnDay
+	retArgs.TripLength = fixedDates
+	inbound, err := SearchOffers(retArgs, excludedAirline, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("return leg on %s: %w", dateKey(returnDay), err)
+	}
+	ret := inbound[0]
+	if ret.departure.Before(out.arrival) {
+		return nil, errors.New("the cheapest return leg departs before the outbound leg arrives")
+	}
+
+	combined := out
+	combined.Price = out.Price + ret.Price
+	combined.End = ret.Start
+	combined.returnDate = ret.departure
+	combined.Airline = out.Airline + " + " + ret.Airline
+	combined.PriceRange = nil
+	combined.Legs = []Message{out, ret}
+	return []Message{combined}, nil
+}
@@ -0,0 +1,51 @@
+package cheapflight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+func TestSplitLegs(t *testing.T) {
+	legPrice := map[string]float64{"SFO": 120, "JFK": 140}
+	api := &stubAPI{
+		priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+			return []flights.Offer{{StartDate: day1, Price: legPrice[args.SrcAirports[0]]}}, nil
+		},
+		offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			o := offerOn(args.Date, legPrice[args.SrcAirports[0]])
+			o.SrcAirportCode, o.DstAirportCode = args.SrcAirports[0], args.DstAirports[0]
+			return []flights.FullOffer{o, offerOn(args.Date, 999)}, nil, nil
+		},
+	}
+	cfg := withStub(Config{SplitLegs: true}, api)
+	args := flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day1, TripLength: 3, SrcAirports: []string{"SFO"}, DstAirports: []string{"JFK"}, Options: roundTrip()}
+
+	messages, err := SearchOffers(args, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var legs []string
+	for _, call := range api.offerCalls {
+		if call.Options.TripType != flights.OneWay {
+			t.Errorf("leg queried as %v, want one way", call.Options.TripType)
+		}
+		legs = append(legs, call.SrcAirports[0]+"→"+call.DstAirports[0]+" "+dateKey(call.Date))
+	}
+	if len(legs) != 2 || legs[0] != "SFO→JFK 2030-03-01" || legs[1] != "JFK→SFO 2030-03-04" {
+		t.Errorf("queried legs %q, want SFO→JFK on 2030-03-01 and JFK→SFO on 2030-03-04", legs)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("got %d results, want 1", len(messages))
+	}
+	m := messages[0]
+	if m.Price != 260 {
+		t.Errorf("combined price = %d, want 260", m.Price)
+	}
+	if len(m.Legs) != 2 || m.Legs[0].Price != 120 || m.Legs[1].Price != 140 {
+		t.Errorf("legs %+v, want the 120 outbound and 140 return", m.Legs)
+	}
+}
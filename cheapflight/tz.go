@@ -0,0 +1,37 @@
+package cheapflight
+
+import (
+	"time"
+	// Embed the time zone database so airport times can be localized on
+	// machines without one installed.
+	_ "time/tzdata"
+
+	"github.com/krisukox/google-flights-api/iata"
+)
+
+// localTimeLayout shows a time with its zone and UTC offset, e.g.
+// "2024-04-11 07:00 CDT (UTC-05:00)".
+const localTimeLayout = "2006-01-02 15:04 MST (UTC-07:00)"
+
+// airportLocation returns the time zone of an IATA airport code, or false if
+// the airport is unknown.
+func airportLocation(code string) (*time.Location, bool) {
+	tz := iata.IATATimeZone(code).Tz
+	if tz == "" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// formatLocalTime formats t in the local time of airport, leaving its zone
+// unchanged for unknown airports.
+func formatLocalTime(t time.Time, airport string) string {
+	if loc, ok := airportLocation(airport); ok {
+		t = t.In(loc)
+	}
+	return t.Format(localTimeLayout)
+}
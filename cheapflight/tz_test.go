@@ -0,0 +1,29 @@
+package cheapflight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatLocalTime(t *testing.T) {
+	tests := []struct {
+		airport string
+		at      time.Time
+		want    string
+	}{
+		{airport: "SFO", at: time.Date(2030, 1, 15, 16, 30, 0, 0, time.UTC), want: "2030-01-15 08:30 PST (UTC-08:00)"},
+		{airport: "SFO", at: time.Date(2030, 7, 15, 16, 30, 0, 0, time.UTC), want: "2030-07-15 09:30 PDT (UTC-07:00)"},
+		{airport: "NRT", at: time.Date(2030, 1, 15, 16, 30, 0, 0, time.UTC), want: "2030-01-16 01:30 JST (UTC+09:00)"},
+		{airport: "XXX", at: time.Date(2030, 1, 15, 16, 30, 0, 0, time.UTC), want: "2030-01-15 16:30 UTC (UTC+00:00)"},
+	}
+	for _, tt := range tests {
+		if got := formatLocalTime(tt.at, tt.airport); got != tt.want {
+			t.Errorf("formatLocalTime(%v, %s) = %q, want %q", tt.at, tt.airport, got, tt.want)
+		}
+	}
+
+	loc, ok := airportLocation("SFO")
+	if !ok || loc.String() != "America/Los_Angeles" {
+		t.Errorf("airportLocation(SFO) = %v, %v, want America/Los_Angeles", loc, ok)
+	}
+}
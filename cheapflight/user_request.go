@@ -1,40 +1,328 @@
 package cheapflight
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/krisukox/google-flights-api/flights"
 )
 
-func ProcessUserRequest() {
-	fmt.Println(os.Args)
-	cheapestArgs, excludedAirline, target, SMSNum, err := ProcessArgs()
+// ErrFailedRoutes is wrapped by the error of a check in which some routes
+// failed to be searched, joined with the failure of each route.
+var ErrFailedRoutes = errors.New("failed routes")
+
+// ProcessUserRequest runs the request in os.Args, checking every watch interval
+// until the start of the date range. It returns an error if the request is
+// invalid, or wrapping ErrFailedRoutes if routes failed in the last check.
+// Failures of earlier checks are logged.
+func ProcessUserRequest() error {
+	return processUserRequest(os.Args, func(error) {})
+}
+
+// StartUserRequest runs the request in args, a command line like os.Args, as
+// ProcessUserRequest does in the background, returning the outcome of the
+// first check once it is done.
+func StartUserRequest(args []string) error {
+	first := make(chan error, 1)
+	var once sync.Once
+	checked := func(err error) {
+		once.Do(func() { first <- err })
+	}
+	go func() {
+		checked(processUserRequest(args, checked))
+	}()
+	return <-first
+}
+
+// parseRequest expands --search-json in args and parses the flags that follow
+// the request arguments. The caller closes the returned Config.
+func parseRequest(args []string) (Config, error) {
+	args, err := expandSearchJSON(args)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var flagArgs []string
+	if len(args) > numArgs {
+		flagArgs = args[numArgs:]
+	}
+	cfg, err := ProcessFlags(flagArgs)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.args = args
+	return cfg, nil
+}
+
+// processUserRequest runs the request in args, calling checked with the error
+// of each check.
+func processUserRequest(args []string, checked func(error)) error {
+	cfg, err := parseRequest(args)
+	if err != nil {
+		return err
+	}
+	defer cfg.Close()
+	args = cfg.args
+
+	log := cfg.logger()
+	log.Info("processing request", "args", args)
+
+	cheapestArgs, excludedAirline, target, SMSNum, err := ProcessArgs(cfg)
+	if err != nil {
+		return err
+	}
+	cfg.applyOptions(&cheapestArgs.Options)
+	if err := validateTravelers(cheapestArgs.Options.Travelers); err != nil {
+		return err
+	}
+
+	var alerted *alertStore
+	if cfg.AlertFile != "" {
+		alerted, err = loadAlertStore(cfg.AlertFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var resume *checkpoint
+	if cfg.CheckpointFile != "" {
+		resume, err = loadCheckpoint(cfg.CheckpointFile, checkpointSearch(args[1:], cheapestArgs))
+		if err != nil {
+			return err
+		}
+		if resume.discarded {
+			log.Warn("checkpoint is of a different search, starting over", "file", cfg.CheckpointFile)
+		}
+	}
+
+	routes, err := requestRoutes(cheapestArgs, cfg)
 	if err != nil {
-		fmt.Println(err.Error())
-		return
+		return err
+	}
+
+	if cfg.CompareCabins {
+		for _, r := range routes {
+			comparison, err := compareCabins(r.apply(cheapestArgs, cfg.Separator), excludedAirline, cfg)
+			if err != nil {
+				log.Error(err.Error())
+				continue
+			}
+			fmt.Println(r)
+			if err := comparison.Render(os.Stdout); err != nil {
+				log.Error(err.Error())
+			}
+		}
+		return nil
 	}
 
 	minFound := math.Inf(1)
+	history := make(map[route][]float64)
+	breaker := newWatchBackoff(watchInterval, cfg.MaxWatchBackoff)
+	cooldown := newAlertCooldown(cfg.AlertCooldown)
+	var checkErr error
+	checks := 0
 	for time.Now().Before(cheapestArgs.RangeStartDate) {
-		var message Message
-		if cheapestArgs.TripLength == -1 {
-			message = GetCheapestOffersFixedDates(cheapestArgs, excludedAirline)
+		w, closeOutput, err := cfg.output()
+		if err != nil {
+			return err
+		}
+		cfg.stream = cfg.newOfferStream(w)
+		cfg.retries.reset()
+		cfg.apiCalls.reset()
+		if checks > 0 {
+			// Only the first check resumes; later ones search every route afresh.
+			resume.Restart()
+		}
+		checks++
+		messages, err := searchRoutes(routes, cheapestArgs, excludedAirline, cfg, history, resume)
+		if err != nil {
+			log.Error(err.Error())
+		}
+		checkErr = err
+
+		if len(messages) == 0 {
+			log.Warn("unable to find flights at this time")
 		} else {
-			message = GetCheapestOffersRange(cheapestArgs, excludedAirline)
+			recordCheck(messages, cfg)
+			if err := render(w, messages, cfg); err != nil {
+				log.Error(err.Error())
+			}
+			if cfg.Copy {
+				if err := copyBestURL(messages, cfg.writeClipboard); err != nil {
+					log.Warn("could not copy booking link to the clipboard", "err", err, "url", cheapestMessage(messages).Url)
+				}
+			}
+
+			now := cfg.now()
+			fresh := unalerted(messages, now, cooldown, alerted, log)
+			notify(fresh, cfg, SMSNum, target, minFound)
+			for _, message := range fresh {
+				cooldown.Record(message.route, now)
+			}
+		}
+		if err := closeOutput(); err != nil {
+			log.Error(err.Error())
+		}
+
+		// Once every route was searched the next check starts over. Otherwise
+		// the checkpoint is kept so that a rerun only retries the failed routes.
+		if checkErr == nil {
+			if err := resume.Clear(); err != nil {
+				log.Error(err.Error())
+			}
 		}
 
-		if message == (Message{}) {
-			fmt.Println(fmt.Errorf("unable to find flights at this time"))
+		checked(checkErr)
+
+		wait := breaker.Next(checkErr != nil && len(messages) == 0)
+		if wait > watchInterval {
+			log.Warn("checks keep failing, backing off", "next_check", wait)
+		}
+		time.Sleep(wait)
+	}
+	return checkErr
+}
+
+// requestRoutes returns the routes searched by the request in cfg.args: from
+// its source, or the --origin-airports, to its destination, or those of the
+// --routes-file, with their reverse under --reverse.
+func requestRoutes(args flights.PriceGraphArgs, cfg Config) ([]route, error) {
+	log := cfg.logger()
+	requestArgs := cfg.requestArgs()
+	routes := []route{{Src: requestArgs[1+startArg], Dst: requestArgs[1+endArg]}}
+	if len(cfg.OriginAirports) > 0 {
+		log.Info("searching from airports near the origin", "airports", cfg.OriginAirports)
+		routes[0].Src = strings.Join(cfg.OriginAirports, cfg.Separator)
+	}
+	if cfg.RoutesFile != "" {
+		var err error
+		routes, err = loadRoutes(cfg.RoutesFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range routes {
+			if err := cfg.checkNotifiers(r.notifiers()); err != nil {
+				return nil, fmt.Errorf("%s: route %s: %w", cfg.RoutesFile, r, err)
+			}
+		}
+	}
+	if cfg.Reverse {
+		routes = withReverse(routes)
+	}
+
+	for _, r := range routes {
+		routeArgs := r.apply(args, cfg.Separator)
+		if err := validateLocations(routeArgs); err != nil {
+			return nil, fmt.Errorf("%s: %w", r, err)
+		}
+		warnAmbiguousCities(log, concat(routeArgs.SrcCities, routeArgs.DstCities))
+		for _, locations := range []string{r.Src, r.Dst} {
+			airports, cities := parseLocations(locations, cfg.Separator)
+			warnCoveredAirports(log, airports, cities)
+		}
+	}
+	return routes, nil
+}
+
+// searchRoutes searches every route, carrying on past routes that fail. The
+// returned error wraps ErrFailedRoutes and the failure of each failed route.
+// Routes already in resume are not searched again and each newly searched
+// route is recorded in it.
+func searchRoutes(routes []route, args flights.PriceGraphArgs, excludedAirline string, cfg Config, history map[route][]float64, resume *checkpoint) ([]Message, error) {
+	var all []Message
+	var errs []error
+	for _, r := range routes {
+		messages, done := resume.Done(r)
+		if done {
+			cfg.logger().Info("resuming from checkpoint, skipping route", "route", r.String())
 		} else {
-			if float64(message.Price) < minFound {
-				messageString := FormatMessageBody(message)
-				SendSMS(messageString, SMSNum)
-			} else if float64(message.Price) < target {
-				messageString := FormatMessageBodyTarget(message, target)
-				SendSMS(messageString, SMSNum)
+			var err error
+			messages, err = SearchOffers(r.apply(args, cfg.Separator), excludedAirline, cfg)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r, err))
+				continue
+			}
+		}
+
+		for i := range messages {
+			messages[i].route = r.String()
+			messages[i].Direction = r.Direction
+			messages[i].notify = r.notifiers()
+			messages[i].Deal = belowBaseline(float64(messages[i].Price), cfg.BaselinePrice) ||
+				isDeal(float64(messages[i].Price), messages[i].PriceRange, cfg.DealBasis, history[r])
+			if messages[i].Deal && !done && cfg.MinDealCount > 1 {
+				if n := countDeals(messages[i].candidates, cfg.DealBasis, cfg.BaselinePrice, history[r]); n < cfg.MinDealCount {
+					cfg.logger().Info("too few deals found to report one", "route", r.String(), "deals", n, "min_deal_count", cfg.MinDealCount)
+					messages[i].Deal = false
+				}
+			}
+			messages[i].DealUnknown = !messages[i].Deal && !dealKnown(messages[i].PriceRange, cfg.DealBasis, history[r])
+			if cfg.BudgetSplit {
+				messages[i].splitBudget(totalTravelers(args.Options.Travelers))
+			}
+			if len(cfg.DisplayCurrencies) > 0 {
+				if err := convertPrices(&messages[i], cfg.DisplayCurrencies, cfg.Rates); err != nil {
+					cfg.logger().Warn("could not convert prices", "err", err)
+				}
+			}
+		}
+		history[r] = append(history[r], float64(messages[0].Price))
+		all = append(all, messages...)
+		if !done {
+			if err := resume.Record(r, messages); err != nil {
+				cfg.logger().Error("saving checkpoint", "err", err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return all, fmt.Errorf("%w:\n%w", ErrFailedRoutes, errors.Join(errs...))
+	}
+	return all, nil
+}
+
+// unalerted returns the messages to alert on, leaving out routes in their
+// cooldown and offers already in the alert store. The returned offers are
+// added to the store, so a restarted watcher does not alert on them again.
+func unalerted(messages []Message, now time.Time, cooldown *alertCooldown, alerted *alertStore, log *slog.Logger) []Message {
+	var fresh []Message
+	for _, message := range messages {
+		if cooldown.Active(message.route, now) {
+			log.Info("route alerted on recently, skipping notifications", "route", message.route, "price", message.Price)
+			continue
+		}
+		if alerted != nil {
+			claimed, err := alerted.Claim(fingerprint(message), message.departure)
+			if err != nil {
+				log.Error(err.Error())
+			} else if !claimed {
+				log.Info("already alerted on this offer, skipping notifications", "price", message.Price, "src", message.Src, "dst", message.Dst)
+				continue
 			}
 		}
-		time.Sleep(12 * time.Hour)
+		fresh = append(fresh, message)
+	}
+	return fresh
+}
+
+// render writes messages to w, the output of the check, in the --format.
+func render(w io.Writer, messages []Message, cfg Config) error {
+	sortMessages(messages, cfg.Sort)
+	if cfg.Anonymize {
+		messages = anonymize(messages)
+	}
+	var renderer Renderer = cfg.renderer()
+	if cfg.SummaryOnly {
+		messages = []Message{cheapestMessage(messages)}
+		if cfg.Format == formatText && cfg.OutputTemplate == nil && cfg.CompactFields == nil {
+			renderer = summaryRenderer{}
+		}
 	}
+	return renderer.Render(w, messages)
 }
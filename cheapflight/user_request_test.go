@@ -0,0 +1,193 @@
+package cheapflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/krisukox/google-flights-api/flights"
+)
+
+// routeStub answers every search with one offer, failing the searches from
+// the airports in failing.
+func routeStub(failing ...string) *stubAPI {
+	fails := func(src []string) bool {
+		for _, f := range failing {
+			if len(src) > 0 && src[0] == f {
+				return true
+			}
+		}
+		return false
+	}
+	return &stubAPI{
+		priceGraph: func(ctx context.Context, args flights.PriceGraphArgs) ([]flights.Offer, error) {
+			if fails(args.SrcAirports) {
+				return nil, errBoom
+			}
+			return []flights.Offer{{StartDate: day1, ReturnDate: day4, Price: 100}}, nil
+		},
+		offers: func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+			if fails(args.SrcAirports) {
+				return nil, nil, errBoom
+			}
+			return []flights.FullOffer{offerOn(args.Date, 100)}, nil, nil
+		},
+	}
+}
+
+func routeArgs() flights.PriceGraphArgs {
+	return flights.PriceGraphArgs{RangeStartDate: day1, RangeEndDate: day2, TripLength: 3, Options: roundTrip()}
+}
+
+func TestSearchRoutesPartialFailure(t *testing.T) {
+	routes := []route{{Src: "SFO", Dst: "JFK"}, {Src: "OAK", Dst: "BOS"}, {Src: "LAX", Dst: "ORD"}}
+	tests := []struct {
+		name       string
+		failing    []string
+		wantRoutes []string
+		wantFailed []string
+	}{
+		{name: "all succeed", wantRoutes: []string{"SFO → JFK", "OAK → BOS", "LAX → ORD"}},
+		{name: "one fails", failing: []string{"OAK"}, wantRoutes: []string{"SFO → JFK", "LAX → ORD"}, wantFailed: []string{"OAK → BOS"}},
+		{name: "all fail", failing: []string{"SFO", "OAK", "LAX"}, wantFailed: []string{"SFO → JFK", "OAK → BOS", "LAX → ORD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := withStub(Config{}, routeStub(tt.failing...))
+			messages, err := searchRoutes(routes, routeArgs(), "", cfg, make(map[route][]float64), nil)
+
+			var got []string
+			for _, m := range messages {
+				got = append(got, m.route)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.wantRoutes, ", ") {
+				t.Errorf("results for routes %q, want %q", got, tt.wantRoutes)
+			}
+
+			if len(tt.wantFailed) == 0 {
+				if err != nil {
+					t.Fatalf("searchRoutes() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrFailedRoutes) || !errors.Is(err, errBoom) {
+				t.Fatalf("searchRoutes() error = %v, want ErrFailedRoutes and the route errors", err)
+			}
+			for _, r := range tt.wantFailed {
+				if !strings.Contains(err.Error(), r+": ") {
+					t.Errorf("error does not report route %s:\n%v", r, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSummaryOnly(t *testing.T) {
+	messages := []Message{
+		{Price: 400, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Url: "https://example.com/a"},
+		{Price: 250, Src: "OAK", Dst: "JFK", Start: "2030-03-02", End: "2030-03-05", Url: "https://example.com/b"},
+		{Price: 300, Src: "SJC", Dst: "JFK", Start: "2030-03-03", End: "2030-03-06", Url: "https://example.com/c"},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: formatText, want: "Cheapest: 250 USD OAK → JFK departing 2030-03-02 returning 2030-03-05 https://example.com/b\n"},
+		{format: formatNDJSON, want: `"price":250`},
+		{format: formatCSV, want: "250,OAK,JFK,2030-03-02,2030-03-05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg, err := ProcessFlags([]string{"--summary-only", "--format=" + tt.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := render(&buf, append([]Message(nil), messages...), cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if tt.format == formatCSV {
+				lines = lines[1:] // header
+			}
+			if len(lines) != 1 {
+				t.Fatalf("got %d result lines, want 1:\n%s", len(lines), buf.String())
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingPriceRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		priceRange  *flights.PriceRange
+		wantDeal    bool
+		wantUnknown bool
+	}{
+		{name: "no price range", priceRange: nil, wantUnknown: true},
+		{name: "below the range", priceRange: &flights.PriceRange{Low: 150, High: 300}, wantDeal: true},
+		{name: "within the range", priceRange: &flights.PriceRange{Low: 80, High: 300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := routeStub()
+			api.offers = func(ctx context.Context, args flights.Args) ([]flights.FullOffer, *flights.PriceRange, error) {
+				return []flights.FullOffer{offerOn(args.Date, 100)}, tt.priceRange, nil
+			}
+			cfg := withStub(Config{DealBasis: dealBasisLow}, api)
+
+			messages, err := searchRoutes([]route{{Src: "SFO", Dst: "JFK"}}, routeArgs(), "", cfg, make(map[route][]float64), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 || messages[0].Price != 100 {
+				t.Fatalf("got %+v, want the offer reported", messages)
+			}
+			if m := messages[0]; m.Deal != tt.wantDeal || m.DealUnknown != tt.wantUnknown {
+				t.Errorf("deal %v unknown %v, want %v and %v", m.Deal, m.DealUnknown, tt.wantDeal, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestRequestArgsAreNotShared(t *testing.T) {
+	saved := append([]string{}, os.Args...)
+	request := func(src, dst string, flags ...string) []string {
+		return append([]string{"runway", "2030-03-01", "2030-03-05", "3", src, dst, "default", "default", "RoundTrip", "default", "default", "default", ""}, flags...)
+	}
+
+	// Concurrent server requests each parse their own arguments.
+	var wg sync.WaitGroup
+	for _, r := range []struct{ src, dst string }{{"SFO", "JFK"}, {"OAK", "BOS"}, {"LAX", "ORD"}} {
+		wg.Add(1)
+		go func(src, dst string) {
+			defer wg.Done()
+			args, _, _, _, err := ProcessArgs(Config{args: request(src, dst)})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(args.SrcAirports, []string{src}) || !reflect.DeepEqual(args.DstAirports, []string{dst}) {
+				t.Errorf("request for %s → %s searched %v → %v", src, dst, args.SrcAirports, args.DstAirports)
+			}
+		}(r.src, r.dst)
+	}
+	wg.Wait()
+
+	// A request failing validation reports its error without touching os.Args.
+	if err := StartUserRequest(request("SFO", "JFK", "--format=bogus")); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("StartUserRequest() error = %v, want the invalid --format", err)
+	}
+	if !reflect.DeepEqual(os.Args, saved) {
+		t.Errorf("os.Args changed to %q", os.Args)
+	}
+}
@@ -0,0 +1,44 @@
+package cheapflight
+
+import "time"
+
+const (
+	// watchInterval is how long the watch loop waits between checks.
+	watchInterval = 12 * time.Hour
+
+	// watchFailureThreshold is how many consecutive failed checks open the
+	// circuit and start backing off.
+	watchFailureThreshold = 3
+)
+
+// watchBackoff is a circuit breaker for the watch loop. Once threshold checks
+// in a row have failed, the wait between checks doubles after every further
+// failure, up to max, until a check succeeds again.
+type watchBackoff struct {
+	interval  time.Duration
+	max       time.Duration
+	threshold int
+	failures  int
+}
+
+func newWatchBackoff(interval, max time.Duration) *watchBackoff {
+	return &watchBackoff{interval: interval, max: max, threshold: watchFailureThreshold}
+}
+
+// Next records the outcome of a check and returns how long to wait before the next one.
+func (b *watchBackoff) Next(failed bool) time.Duration {
+	if !failed {
+		b.failures = 0
+		return b.interval
+	}
+
+	b.failures++
+	wait := b.interval
+	for i := b.threshold; i <= b.failures && wait < b.max; i++ {
+		wait *= 2
+	}
+	if wait > b.max {
+		wait = b.max
+	}
+	return wait
+}
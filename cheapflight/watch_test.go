@@ -0,0 +1,28 @@
+package cheapflight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchBackoff(t *testing.T) {
+	b := newWatchBackoff(time.Hour, 8*time.Hour)
+	steps := []struct {
+		failed bool
+		want   time.Duration
+	}{
+		{failed: true, want: time.Hour},
+		{failed: true, want: time.Hour},
+		{failed: true, want: 2 * time.Hour},
+		{failed: true, want: 4 * time.Hour},
+		{failed: true, want: 8 * time.Hour},
+		{failed: true, want: 8 * time.Hour},
+		{failed: false, want: time.Hour},
+		{failed: true, want: time.Hour},
+	}
+	for i, step := range steps {
+		if got := b.Next(step.failed); got != step.want {
+			t.Errorf("check %d (failed %v): waiting %s, want %s", i+1, step.failed, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cheapflight
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// webhookFormats are the --format values rendering a JSON payload, which the
+// webhook posts as is. With the default text format the webhook posts json.
+var webhookFormats = map[string]bool{
+	formatJSON:         true,
+	formatPrettyJSON:   true,
+	formatSlack:        true,
+	formatDiscord:      true,
+	formatCombinedJSON: true,
+	formatFlatJSON:     true,
+}
+
+func webhookFormatNames() []string {
+	var names []string
+	for name := range webhookFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// webhookRenderer renders the webhook payload, which is always sent as
+// application/json. Colors are left out of pretty-json as they are not JSON.
+func (c Config) webhookRenderer() Renderer {
+	switch {
+	case c.Format == formatPrettyJSON:
+		return prettyJSONRenderer{}
+	case webhookFormats[c.Format]:
+		return c.renderer()
+	}
+	return jsonRenderer{}
+}
+
+func SendWebhook(payload []byte, url string) error {
+	resp, err := http.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
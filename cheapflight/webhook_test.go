@@ -0,0 +1,77 @@
+package cheapflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookPayloadIsJSON(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: formatText},
+		{format: formatJSON},
+		{format: formatPrettyJSON},
+		{format: formatSlack},
+		{format: formatDiscord},
+		{format: formatCSV, wantErr: true},
+		{format: formatHTML, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var contentType string
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ = io.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			cfg, err := ProcessFlags([]string{"--webhook-url=" + server.URL, "--format=" + tt.format, "--color=always"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessFlags error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			messages := []Message{{Price: 250, Src: "SFO", Dst: "JFK", Start: "2030-03-01", End: "2030-03-04", Url: "https://example.com/book"}}
+			if err := notifyWebhook(alert{messages: messages}, cfg); err != nil {
+				t.Fatal(err)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			if !json.Valid(body) {
+				t.Errorf("posted payload is not JSON: %s", body)
+			}
+		})
+	}
+}
+
+func TestWebhookLogsToLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	output := captureStd(t)
+	var logged bytes.Buffer
+	cfg := Config{WebhookURL: server.URL, log: slog.New(slog.NewTextHandler(&logged, nil))}
+	if err := notifyWebhook(alert{messages: renderMessages}, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stdout only carries results, so that --format json stays parseable.
+	if out := output(); out != "" {
+		t.Errorf("printed %q, want nothing", out)
+	}
+	if !strings.Contains(logged.String(), "webhook sent") {
+		t.Errorf("logged %q, want the webhook being sent", logged.String())
+	}
+}
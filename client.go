@@ -9,18 +9,19 @@ import (
 )
 
 type req struct {
-	RangeStartDate   string `json:"range-start-date"`
-	RangeEndDate     string `json:"range-end-date"`
-	TripLength       string `json:"trip-length"`
-	Src              string `json:"trip-src"`
-	Dst              string `json:"trip-dst"`
-	Travelers        string `json:"travelers"`
-	Class            string `json:"class"`
-	TripType         string `json:"trip-type"`
-	Stops            string `json:"stops"`
-	ExcludedAirlines string `json:"excluded-airlines"` // Added field for excluded airlines
-	Target           string `json:"target"`
-	SMSNumber        string `json:"sms-number"`
+	RangeStartDate   string   `json:"range-start-date"`
+	RangeEndDate     string   `json:"range-end-date"`
+	TripLength       string   `json:"trip-length"`
+	Src              string   `json:"trip-src"`
+	Dst              string   `json:"trip-dst"`
+	Travelers        string   `json:"travelers"`
+	Class            string   `json:"class"`
+	TripType         string   `json:"trip-type"`
+	Stops            string   `json:"stops"`
+	ExcludedAirlines string   `json:"excluded-airlines"` // Added field for excluded airlines
+	Target           string   `json:"target"`
+	SMSNumber        string   `json:"sms-number"`
+	Flags            []string `json:"flags"`
 }
 
 func main() {
@@ -2,29 +2,35 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	runway "github.com/ajhingran/runway/cheapflight"
 	"io"
+	"log"
+	"net"
 	"net/http"
 	"os"
 	"reflect"
 )
 
 const (
-	address = ":8080"
+	address     = ":8080"
+	grpcAddress = ":9090"
 )
 
 type UserRequest struct {
-	RangeStartDate   string `json:"range-start-date"`
-	RangeEndDate     string `json:"range-end-date"`
-	TripLength       string `json:"trip-length"`
-	Src              string `json:"trip-src"`
-	Dst              string `json:"trip-dst"`
-	Travelers        string `json:"travelers"`
-	Class            string `json:"class"`
-	TripType         string `json:"trip-type"`
-	Stops            string `json:"stops"`
-	ExcludedAirlines string `json:"excluded-airlines"` // Added field for excluded airlines
-	Target           string `json:"target"`
+	RangeStartDate   string   `json:"range-start-date"`
+	RangeEndDate     string   `json:"range-end-date"`
+	TripLength       string   `json:"trip-length"`
+	Src              string   `json:"trip-src"`
+	Dst              string   `json:"trip-dst"`
+	Travelers        string   `json:"travelers"`
+	Class            string   `json:"class"`
+	TripType         string   `json:"trip-type"`
+	Stops            string   `json:"stops"`
+	ExcludedAirlines string   `json:"excluded-airlines"` // Added field for excluded airlines
+	Target           string   `json:"target"`
+	SMSNumber        string   `json:"sms-number"`
+	Flags            []string `json:"flags"`
 }
 
 func processRequest(w http.ResponseWriter, r *http.Request) {
@@ -66,13 +72,30 @@ func processRequest(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	os.Args = args
-	// Print the incoming data
-	go runway.ProcessUserRequest()
+	args = append(args, userRequest.Flags...)
+
+	// The request keeps watching in the background once its first check is done
+	err = runway.StartUserRequest(args)
+	if errors.Is(err, runway.ErrFailedRoutes) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("502 - " + err.Error()))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("400 - " + err.Error()))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Request Configured"))
 }
 
+func handleBadge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	runway.RenderLatestBadge(w)
+}
+
 func handleHello(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Welcome to Runway"))
 }
@@ -88,12 +111,48 @@ func handleRequest(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
+	// "serve" answers searches over gRPC instead of running a request.
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		lis, err := net.Listen("tcp", grpcAddress)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("serving gRPC searches at %s", grpcAddress)
+		log.Fatal(runway.NewGRPCServer().Serve(lis))
+	}
+	// "doctor" checks that Google Flights can be reached instead of running a request.
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		if err := runway.Doctor(os.Stdout, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// "list-searches" prints the saved searches instead of running a request.
+	if len(os.Args) > 1 && os.Args[1] == "list-searches" {
+		if err := runway.ListSearches(os.Stdout, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// "dashboard" runs the request that follows it as usual and points at the
+	// page showing its latest fares.
+	if len(os.Args) > 1 && os.Args[1] == "dashboard" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		log.Printf("serving the fare dashboard at http://localhost%s/dashboard", address)
+	}
 	if len(os.Args) != 1 {
-		go runway.ProcessUserRequest()
+		go func() {
+			if err := runway.ProcessUserRequest(); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		}()
 	}
 
 	http.HandleFunc("/", handleHello)
 	http.HandleFunc("/request", handleRequest)
 	http.HandleFunc("/request/", handleRequest)
+	http.HandleFunc("/badge", handleBadge)
+	http.HandleFunc("/dashboard", runway.ServeDashboard)
 	http.ListenAndServe(address, nil)
 }
@@ -0,0 +1,112 @@
+// Search results as written by --format protobuf and served by ./runway serve.
+// The Go code in runwayv1 is generated from this file, see the README.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: runway.proto
+
+package runwayv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Runway_Search_FullMethodName = "/runway.v1.Runway/Search"
+)
+
+// RunwayClient is the client API for Runway service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RunwayClient interface {
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResults, error)
+}
+
+type runwayClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRunwayClient(cc grpc.ClientConnInterface) RunwayClient {
+	return &runwayClient{cc}
+}
+
+func (c *runwayClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResults, error) {
+	out := new(SearchResults)
+	err := c.cc.Invoke(ctx, Runway_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RunwayServer is the server API for Runway service.
+// All implementations must embed UnimplementedRunwayServer
+// for forward compatibility
+type RunwayServer interface {
+	Search(context.Context, *SearchRequest) (*SearchResults, error)
+	mustEmbedUnimplementedRunwayServer()
+}
+
+// UnimplementedRunwayServer must be embedded to have forward compatible implementations.
+type UnimplementedRunwayServer struct {
+}
+
+func (UnimplementedRunwayServer) Search(context.Context, *SearchRequest) (*SearchResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedRunwayServer) mustEmbedUnimplementedRunwayServer() {}
+
+// UnsafeRunwayServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RunwayServer will
+// result in compilation errors.
+type UnsafeRunwayServer interface {
+	mustEmbedUnimplementedRunwayServer()
+}
+
+func RegisterRunwayServer(s grpc.ServiceRegistrar, srv RunwayServer) {
+	s.RegisterService(&Runway_ServiceDesc, srv)
+}
+
+func _Runway_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunwayServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runway_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunwayServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Runway_ServiceDesc is the grpc.ServiceDesc for Runway service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Runway_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "runway.v1.Runway",
+	HandlerType: (*RunwayServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _Runway_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "runway.proto",
+}